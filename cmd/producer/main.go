@@ -40,30 +40,30 @@ type Delivery struct {
 }
 
 type Payment struct {
-	Transaction  string  `json:"transaction"`
-	RequestID    string  `json:"request"`
-	Currency     string  `json:"currency"`
-	Provider     string  `json:"provider"`
-	Amount       float64 `json:"amount"`
-	PaymentDt    int64   `json:"payment_dt"`
-	Bank         string  `json:"bank"`
-	DeliveryCost float64 `json:"delivery_cost"`
-	GoodsTotal   int     `json:"goods_total"`
-	CustomFee    float64 `json:"custom_fee"`
+	Transaction  string      `json:"transaction"`
+	RequestID    string      `json:"request"`
+	Currency     string      `json:"currency"`
+	Provider     string      `json:"provider"`
+	Amount       json.Number `json:"amount"`
+	PaymentDt    int64       `json:"payment_dt"`
+	Bank         string      `json:"bank"`
+	DeliveryCost json.Number `json:"delivery_cost"`
+	GoodsTotal   int         `json:"goods_total"`
+	CustomFee    json.Number `json:"custom_fee"`
 }
 
 type Item struct {
-	ChrtID      int64   `json:"chrt_id"`
-	TrackNumber string  `json:"track_number"`
-	Price       float64 `json:"price"`
-	Rid         string  `json:"rid"`
-	Name        string  `json:"name"`
-	Sale        int     `json:"sale"`
-	Size        string  `json:"size"`
-	TotalPrice  float64 `json:"total_price"`
-	NmID        int64   `json:"nm_id"`
-	Brand       string  `json:"brand"`
-	Status      int     `json:"status"`
+	ChrtID      int64       `json:"chrt_id"`
+	TrackNumber string      `json:"track_number"`
+	Price       json.Number `json:"price"`
+	Rid         string      `json:"rid"`
+	Name        string      `json:"name"`
+	Sale        int         `json:"sale"`
+	Size        string      `json:"size"`
+	TotalPrice  json.Number `json:"total_price"`
+	NmID        int64       `json:"nm_id"`
+	Brand       string      `json:"brand"`
+	Status      int         `json:"status"`
 }
 
 // Тестовые данные
@@ -75,6 +75,11 @@ var (
 	banks  = []string{"Sberbank", "VTB", "Gazprombank", "Alfa Bank", "Raiffeisen", "Tinkoff"}
 )
 
+// kopecks переводит сумму в копейках в рубли без потери точности
+func kopecks(amount int64) json.Number {
+	return json.Number(fmt.Sprintf("%d.%02d", amount/100, amount%100))
+}
+
 // generateRandomOrder создает случайный заказ
 func generateRandomOrder() Order {
 	orderUID := fmt.Sprintf("order_%d_%d", time.Now().Unix(), rand.Intn(10000))
@@ -83,23 +88,24 @@ func generateRandomOrder() Order {
 	// Генерация товаров
 	itemCount := rand.Intn(3) + 1 // 1-3 товара
 	var orderItems []Item
-	totalPrice := 0.0
+	var totalPrice int64
 
+	// Суммы считаются в копейках, чтобы не было ошибок округления
 	for i := 0; i < itemCount; i++ {
-		price := rand.Float64() // 500-5500
-		sale := rand.Intn(50)   // 0-49% скидка
-		finalPrice := price * (100 - float64(sale)) / 100
+		price := rand.Int63n(500000) + 50000 // 500-5500
+		sale := rand.Intn(50)                // 0-49% скидка
+		finalPrice := price * int64(100-sale) / 100
 		totalPrice += finalPrice
 
 		item := Item{
 			ChrtID:      int64(rand.Intn(1000000) + 1000000),
 			TrackNumber: trackNumber,
-			Price:       price,
+			Price:       kopecks(price),
 			Rid:         fmt.Sprintf("rid_%d", rand.Intn(1000000)),
 			Name:        items[rand.Intn(len(items))],
 			Sale:        sale,
 			Size:        []string{"XS", "S", "M", "L", "XL"}[rand.Intn(5)],
-			TotalPrice:  finalPrice,
+			TotalPrice:  kopecks(finalPrice),
 			NmID:        int64(rand.Intn(10000000) + 1000000),
 			Brand:       brands[rand.Intn(len(brands))],
 			Status:      []int{200, 201, 202}[rand.Intn(3)],
@@ -107,7 +113,7 @@ func generateRandomOrder() Order {
 		orderItems = append(orderItems, item)
 	}
 
-	deliveryCost := float64(rand.Intn(1000) + 200)
+	deliveryCost := int64(rand.Intn(1000)+200) * 100
 
 	return Order{
 		OrderUID:    orderUID,
@@ -127,12 +133,12 @@ func generateRandomOrder() Order {
 			RequestID:    fmt.Sprintf("req_%d", rand.Intn(1000000)),
 			Currency:     "RUB",
 			Provider:     "wbpay",
-			Amount:       totalPrice + deliveryCost,
+			Amount:       kopecks(totalPrice + deliveryCost),
 			PaymentDt:    time.Now().Unix(),
 			Bank:         banks[rand.Intn(len(banks))],
-			DeliveryCost: deliveryCost,
+			DeliveryCost: kopecks(deliveryCost),
 			GoodsTotal:   rand.Intn(100),
-			CustomFee:    float64(rand.Intn(100)),
+			CustomFee:    kopecks(int64(rand.Intn(100)) * 100),
 		},
 		Items:             orderItems,
 		Locale:            []string{"ru", "en"}[rand.Intn(2)],
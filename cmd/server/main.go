@@ -2,20 +2,28 @@ package main
 
 import (
 	"context"
-	"github.com/IBM/sarama"
+	"encoding/json"
+	"flag"
 	"github.com/biryanim/wb_tech_L0/internal/api"
+	"github.com/biryanim/wb_tech_L0/internal/client/cache"
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/guarded_cache"
 	"github.com/biryanim/wb_tech_L0/internal/client/cache/lru_cache"
-	"github.com/biryanim/wb_tech_L0/internal/client/db/pg"
-	"github.com/biryanim/wb_tech_L0/internal/client/db/transaction"
-	kafkaConsumer "github.com/biryanim/wb_tech_L0/internal/client/kafka/consumer"
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/redis_cache"
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/tiered_cache"
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
 	"github.com/biryanim/wb_tech_L0/internal/config"
 	"github.com/biryanim/wb_tech_L0/internal/config/env"
-	orderRepo "github.com/biryanim/wb_tech_L0/internal/repository/order"
-	"github.com/biryanim/wb_tech_L0/internal/service"
-	orderSaverConsumer "github.com/biryanim/wb_tech_L0/internal/service/consumer/order_saver"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/biryanim/wb_tech_L0/internal/service/access_log"
+	"github.com/biryanim/wb_tech_L0/internal/service/cache_admin"
+	"github.com/biryanim/wb_tech_L0/internal/service/export"
 	"github.com/biryanim/wb_tech_L0/internal/service/order"
+	"github.com/biryanim/wb_tech_L0/internal/service/warmup"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 	"log"
 	"net/http"
 	"os"
@@ -24,19 +32,33 @@ import (
 	"syscall"
 )
 
-const cacheCap = 5
+const (
+	negativeCacheCap  = 1000
+	statusGroupSuffix = "-status"
+)
+
+var (
+	storage     = flag.String("storage", storagePostgres, "where orders are stored: postgres or memory")
+	seedPath    = flag.String("seed", "", "NDJSON file with orders to preload into the memory storage")
+	autoMigrate = flag.Bool("auto-migrate", false, "apply pending migrations on startup")
+)
 
 func main() {
 	ctx := context.Background()
 
+	flag.Parse()
+
 	err := config.Load("local.env")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	pgConfig, err := env.NewPGConfig()
-	if err != nil {
-		log.Fatalf("failed to load pg config: %v", err)
+	if flag.Arg(0) == "migrate" {
+		err = migrate(ctx, flag.Arg(1))
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
 	httpConfig, err := env.NewHTTPConfig()
@@ -44,57 +66,135 @@ func main() {
 		log.Fatalf("failed to load http config: %v", err)
 	}
 
-	kafkaConsumerConfig, err := env.NewKafkaConsumerConfig()
+	cacheConfig, err := env.NewCacheConfig()
 	if err != nil {
-		log.Fatalf("failed to load kafka consumer config: %v", err)
+		log.Fatalf("failed to load cache config: %v", err)
 	}
 
-	consumerGroup, err := sarama.NewConsumerGroup(
-		kafkaConsumerConfig.Brokers(),
-		kafkaConsumerConfig.GroupID(),
-		kafkaConsumerConfig.Config(),
-	)
+	warmUpConfig, err := env.NewWarmUpConfig()
 	if err != nil {
-		log.Fatalf("failed to create consumer group: %v", err)
+		log.Fatalf("failed to load warm-up config: %v", err)
 	}
-	consumerGroupHandler := kafkaConsumer.NewGroupHandler()
-	consumer := kafkaConsumer.NewConsumer(consumerGroup, consumerGroupHandler)
-	defer consumer.Close()
 
-	dbcClient, err := pg.New(ctx, pgConfig.DSN())
+	exportConfig, err := env.NewExportConfig()
 	if err != nil {
-		log.Fatalf("failed to initialize db client: %v", err)
+		log.Fatalf("failed to load export config: %v", err)
 	}
-	defer dbcClient.Close()
 
-	cacheClient := lru_cache.New(cacheCap)
+	var cacheTiers []cache.Client
+	var localCache *lru_cache.Cache
+	if cacheConfig.Type() != config.CacheTypeRedis {
+		// Entries nobody reads are not refreshed, so they expire at hard ttl.
+		localCache = lru_cache.NewWithTTL(cacheConfig.Capacity(), cacheConfig.HardTTL())
+		cacheTiers = append(cacheTiers, localCache)
+	}
+	if cacheConfig.Type() != config.CacheTypeLRU {
+		redisConfig, err := env.NewRedisConfig()
+		if err != nil {
+			log.Fatalf("failed to load redis config: %v", err)
+		}
 
-	txManager := transaction.NewTransactionManager(dbcClient.DB())
-	orderRepository := orderRepo.NewRepository(dbcClient)
-	ordSaverConsumer := orderSaverConsumer.NewService(orderRepository, consumer, txManager, cacheClient)
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     redisConfig.Addr(),
+			Password: redisConfig.Password(),
+			DB:       redisConfig.DB(),
+		})
+		defer redisClient.Close()
+
+		err = redisClient.Ping(ctx).Err()
+		if err != nil {
+			log.Fatalf("failed to connect to redis: %v", err)
+		}
+
+		redisTTL := redisConfig.TTL()
+		if hardTTL := cacheConfig.HardTTL(); hardTTL > 0 && (redisTTL == 0 || redisTTL > hardTTL) {
+			redisTTL = hardTTL
+		}
+		cacheTiers = append(cacheTiers, redis_cache.New(redisClient, redisConfig.KeyPrefix(), redisTTL, redis_cache.DecodeJSON[model.OrderSnapshot]()))
+	}
+	cacheClient := guarded_cache.NewReadThrough(tiered_cache.New(cacheTiers...))
+	notFoundCache := guarded_cache.New(lru_cache.NewWithTTL(negativeCacheCap, cacheConfig.NegativeTTL()))
 
 	wg := &sync.WaitGroup{}
-	wg.Add(2)
 	ctx, cancel := context.WithCancel(ctx)
 
+	var orderRepository repository.OrderRepository
+	var txManager db.TxManager
+	switch *storage {
+	case storagePostgres:
+		if *autoMigrate {
+			err = migrate(ctx, "up")
+			if err != nil {
+				log.Fatalf("failed to migrate: %v", err)
+			}
+		}
+
+		var closeStorage func()
+		orderRepository, txManager, closeStorage = runPostgres(ctx, wg, cacheClient, notFoundCache)
+		defer closeStorage()
+	case storageMemory:
+		orderRepository, txManager = newMemoryStorage(ctx, *seedPath)
+	default:
+		log.Fatalf("unknown storage: %s", *storage)
+	}
+
+	wg.Add(2)
+	accessLogService := access_log.NewService(orderRepository, warmUpConfig.AccessLogFlushInterval())
 	go func() {
 		defer wg.Done()
-		err = ordSaverConsumer.RunConsumer(ctx)
+		err := accessLogService.Run(ctx)
 		if err != nil && !errors.Is(err, context.Canceled) {
-			log.Printf("failed to run consumer: %s", err.Error())
+			log.Printf("failed to run access log: %s", err.Error())
 		}
 	}()
 
-	orderService := order.NewService(orderRepository, txManager, cacheClient)
-	orderImpl := api.NewImplementation(orderService)
+	orderService := order.NewService(
+		orderRepository,
+		txManager,
+		cacheClient,
+		notFoundCache,
+		accessLogService,
+		cacheConfig.SoftTTL(),
+		cacheConfig.HardTTL(),
+	)
+	cacheAdminService := cache_admin.NewService(cacheClient, notFoundCache, orderService)
+	warmUpService := warmup.NewService(orderRepository, cacheClient, warmUpConfig)
+	exportService := export.NewService(orderRepository, exportConfig)
+	orderImpl := api.NewImplementation(orderService, cacheAdminService, warmUpService, exportService)
 
-	err = restoreCache(ctx, cacheCap, orderService)
-	if err != nil {
-		log.Printf("failed to restore cache: %s", err.Error())
+	if restoreSnapshot(cacheConfig, localCache) {
+		warmUpService.Skip(ctx)
+	} else {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := warmUpService.Run(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("failed to warm up cache: %s", err.Error())
+			}
+		}()
 	}
 
 	router := gin.Default()
+	router.Use(api.ErrorHandler())
 	router.GET("order/:order_uid", orderImpl.GetOrder)
+	router.GET("order/:order_uid/history", orderImpl.GetOrderHistory)
+	router.GET("orders", orderImpl.ListOrders)
+	router.GET("orders/search", orderImpl.SearchOrders)
+
+	admin := router.Group("/admin", api.AdminAuth(httpConfig.AdminToken()))
+	cacheAdmin := admin.Group("/cache")
+	cacheAdmin.GET("/keys", orderImpl.ListCacheKeys)
+	cacheAdmin.GET("/keys/:key", orderImpl.GetCacheEntry)
+	cacheAdmin.DELETE("/keys/:key", orderImpl.EvictCacheKey)
+	cacheAdmin.DELETE("/keys", orderImpl.FlushCache)
+	cacheAdmin.GET("/stats", orderImpl.GetCacheStats)
+	cacheAdmin.GET("/warmup", orderImpl.GetWarmUpProgress)
+
+	admin.GET("/orders/export", orderImpl.ExportOrders)
+
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	router.Static("/static", "./static")
 	router.LoadHTMLGlob("templates/*")
 	router.GET("/", func(c *gin.Context) {
@@ -109,12 +209,19 @@ func main() {
 	go func() {
 		defer wg.Done()
 		err = httpServer.ListenAndServe()
-		if err != nil && errors.Is(err, http.ErrServerClosed) {
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("failed to start http server: %v", err)
 		}
 	}()
 
 	gracefulShutdown(ctx, cancel, httpServer, wg)
+
+	if localCache != nil && len(cacheConfig.SnapshotPath()) != 0 {
+		err = localCache.WriteSnapshot(cacheConfig.SnapshotPath())
+		if err != nil {
+			log.Printf("failed to write cache snapshot: %s", err.Error())
+		}
+	}
 }
 
 func gracefulShutdown(ctx context.Context, cancel context.CancelFunc, httpServer *http.Server, wg *sync.WaitGroup) {
@@ -141,10 +248,29 @@ func waitSignal() chan os.Signal {
 	return sigterm
 }
 
-func restoreCache(ctx context.Context, cacheCap int, serv service.OrderService) error {
-	err := serv.RestoreCache(ctx, cacheCap)
+func restoreSnapshot(cacheConfig config.CacheConfig, localCache *lru_cache.Cache) bool {
+	if localCache == nil || len(cacheConfig.SnapshotPath()) == 0 {
+		return false
+	}
+
+	restored, err := localCache.ReadSnapshot(cacheConfig.SnapshotPath(), cacheConfig.SnapshotMaxAge(), decodeOrder)
 	if err != nil {
-		return err
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("failed to read cache snapshot: %s", err.Error())
+		}
+		return false
 	}
-	return nil
+
+	log.Printf("restored %d orders from cache snapshot", restored)
+	return true
+}
+
+func decodeOrder(data []byte) (interface{}, error) {
+	snapshot := &model.OrderSnapshot{}
+	err := json.Unmarshal(data, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
 }
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db/migrator"
+	"github.com/biryanim/wb_tech_L0/internal/config/env"
+	"github.com/biryanim/wb_tech_L0/migrations"
+	"github.com/pkg/errors"
+)
+
+// migrate runs one of the "server migrate up|down|status" commands with the
+// migrations embedded into the binary.
+func migrate(ctx context.Context, command string) error {
+	migrationConfig, err := env.NewMigrationConfig()
+	if err != nil {
+		return err
+	}
+
+	m, err := migrator.New(migrationConfig.DSN(), migrations.FS)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch command {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx)
+	case "status":
+		return m.Status(ctx, os.Stdout)
+	default:
+		return errors.Errorf("unknown migrate command %q, want up, down or status", command)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/IBM/sarama"
+	"github.com/biryanim/wb_tech_L0/internal/client/cache"
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	"github.com/biryanim/wb_tech_L0/internal/client/db/instrumented"
+	"github.com/biryanim/wb_tech_L0/internal/client/db/pg"
+	"github.com/biryanim/wb_tech_L0/internal/client/db/transaction"
+	kafkaConsumer "github.com/biryanim/wb_tech_L0/internal/client/kafka/consumer"
+	"github.com/biryanim/wb_tech_L0/internal/config/env"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/biryanim/wb_tech_L0/internal/repository/memory"
+	orderRepo "github.com/biryanim/wb_tech_L0/internal/repository/order"
+	"github.com/biryanim/wb_tech_L0/internal/service/cache_invalidator"
+	orderSaverConsumer "github.com/biryanim/wb_tech_L0/internal/service/consumer/order_saver"
+	"github.com/biryanim/wb_tech_L0/internal/service/consumer/status_updater"
+	"github.com/biryanim/wb_tech_L0/internal/service/retention"
+	"github.com/pkg/errors"
+)
+
+const (
+	storagePostgres = "postgres"
+	storageMemory   = "memory"
+
+	seedSource = "seed"
+)
+
+// runPostgres connects to Postgres and starts everything that feeds it or
+// depends on it: the Kafka consumers, the cache invalidator and retention.
+// The returned function closes the connections once they are done.
+func runPostgres(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	cacheClient cache.Client,
+	notFoundCache cache.Client,
+) (repository.OrderRepository, db.TxManager, func()) {
+	pgConfig, err := env.NewPGConfig()
+	if err != nil {
+		log.Fatalf("failed to load pg config: %v", err)
+	}
+
+	kafkaConsumerConfig, err := env.NewKafkaConsumerConfig()
+	if err != nil {
+		log.Fatalf("failed to load kafka consumer config: %v", err)
+	}
+
+	retentionConfig, err := env.NewRetentionConfig()
+	if err != nil {
+		log.Fatalf("failed to load retention config: %v", err)
+	}
+
+	consumerGroup, err := sarama.NewConsumerGroup(
+		kafkaConsumerConfig.Brokers(),
+		kafkaConsumerConfig.GroupID(),
+		kafkaConsumerConfig.Config(),
+	)
+	if err != nil {
+		log.Fatalf("failed to create consumer group: %v", err)
+	}
+	consumerGroupHandler := kafkaConsumer.NewGroupHandler()
+	consumer := kafkaConsumer.NewConsumer(consumerGroup, consumerGroupHandler)
+
+	statusConsumerGroup, err := sarama.NewConsumerGroup(
+		kafkaConsumerConfig.Brokers(),
+		kafkaConsumerConfig.GroupID()+statusGroupSuffix,
+		kafkaConsumerConfig.Config(),
+	)
+	if err != nil {
+		log.Fatalf("failed to create status consumer group: %v", err)
+	}
+	statusConsumer := kafkaConsumer.NewConsumer(statusConsumerGroup, kafkaConsumer.NewGroupHandler())
+
+	dbcClient, err := pg.New(ctx, pgConfig.DSN(), pgConfig.ReplicaDSNs()...)
+	if err != nil {
+		log.Fatalf("failed to initialize db client: %v", err)
+	}
+	closeFn := func() {
+		statusConsumer.Close()
+		consumer.Close()
+		dbcClient.Close()
+	}
+	dbcClient = instrumented.NewClient(dbcClient, pgConfig.SlowQueryThreshold())
+
+	txManager := transaction.NewTransactionManager(dbcClient.DB())
+	orderRepository := orderRepo.NewRepository(dbcClient)
+	ordSaverConsumer := orderSaverConsumer.NewService(orderRepository, consumer, txManager, cacheClient, notFoundCache)
+	statusUpdaterConsumer := status_updater.NewService(orderRepository, statusConsumer, txManager, cacheClient)
+
+	cacheInvalidator := cache_invalidator.NewService(pg.NewListener(pgConfig.DSN()), cacheClient, notFoundCache)
+	retentionService := retention.NewService(orderRepository, txManager, retentionConfig)
+
+	wg.Add(4)
+
+	go func() {
+		defer wg.Done()
+		err := ordSaverConsumer.RunConsumer(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("failed to run consumer: %s", err.Error())
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		err := statusUpdaterConsumer.RunConsumer(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("failed to run status consumer: %s", err.Error())
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		err := cacheInvalidator.RunInvalidator(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("failed to run cache invalidator: %s", err.Error())
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		err := retentionService.Run(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("failed to run retention: %s", err.Error())
+		}
+	}()
+
+	return orderRepository, txManager, closeFn
+}
+
+// newMemoryStorage keeps orders in process memory, so the API and the UI can
+// be tried without Postgres and Kafka. Orders can be preloaded from an NDJSON
+// file, e.g. one written by the export endpoint.
+func newMemoryStorage(ctx context.Context, seedPath string) (repository.OrderRepository, db.TxManager) {
+	orderRepository := memory.NewRepository()
+	txManager := memory.NewTxManager(orderRepository)
+
+	if len(seedPath) != 0 {
+		seeded, err := seedOrders(ctx, orderRepository, txManager, seedPath)
+		if err != nil {
+			log.Fatalf("failed to seed orders: %v", err)
+		}
+		log.Printf("seeded %d orders from %s", seeded, seedPath)
+	}
+
+	return orderRepository, txManager
+}
+
+func seedOrders(ctx context.Context, orderRepository repository.OrderRepository, txManager db.TxManager, path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var seeded int
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		order := &model.Order{}
+		err = json.Unmarshal(scanner.Bytes(), order)
+		if err != nil {
+			return seeded, errors.Wrapf(err, "invalid order on line %d", seeded+1)
+		}
+
+		err = txManager.ReadCommited(repository.WithSource(ctx, seedSource), func(ctx context.Context) error {
+			return seedOrder(ctx, orderRepository, order)
+		})
+		if err != nil {
+			return seeded, errors.Wrapf(err, "failed to seed order %s", order.OrderUID)
+		}
+		seeded++
+	}
+
+	return seeded, scanner.Err()
+}
+
+func seedOrder(ctx context.Context, orderRepository repository.OrderRepository, order *model.Order) error {
+	_, err := orderRepository.CreateOrder(ctx, order)
+	if err != nil {
+		return err
+	}
+
+	_, err = orderRepository.CreateDelivery(ctx, order.OrderUID, &order.Delivery)
+	if err != nil {
+		return err
+	}
+
+	_, err = orderRepository.CreatePayment(ctx, order.OrderUID, &order.Payment)
+	if err != nil {
+		return err
+	}
+
+	err = orderRepository.CreateItems(ctx, order.OrderUID, order.Items)
+	if err != nil {
+		return err
+	}
+
+	timeline := order.Timeline
+	if len(timeline) == 0 {
+		timeline = []model.StatusChange{{To: model.OrderStatusCreated, ChangedAt: order.DateCreated}}
+	}
+	for _, change := range timeline {
+		err = orderRepository.CreateStatusChange(ctx, order.OrderUID, &change)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(order.Status) != 0 {
+		err = orderRepository.UpdateOrderStatus(ctx, order.OrderUID, order.Status)
+		if err != nil {
+			return err
+		}
+	}
+
+	return orderRepository.RecordOrderVersion(ctx, order.OrderUID)
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
+	"github.com/gin-gonic/gin"
+)
+
+const bearerPrefix = "Bearer "
+
+// AdminAuth lets through requests that carry token as a bearer token. With
+// an empty token every request is rejected.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		given, ok := strings.CutPrefix(header, bearerPrefix)
+		if len(token) == 0 || !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.Error(errs.Unauthenticated("invalid admin token"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
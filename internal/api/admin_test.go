@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveAdmin(token string, header string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/admin", AdminAuth(token), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	if len(header) != 0 {
+		req.Header.Set("Authorization", header)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		status int
+	}{
+		{name: "valid token", token: "secret", header: "Bearer secret", status: http.StatusNoContent},
+		{name: "wrong token", token: "secret", header: "Bearer other", status: http.StatusUnauthorized},
+		{name: "no header", token: "secret", status: http.StatusUnauthorized},
+		{name: "not bearer", token: "secret", header: "secret", status: http.StatusUnauthorized},
+		{name: "disabled", token: "", header: "Bearer ", status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveAdmin(tt.token, tt.header)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (i *Implementation) ListCacheKeys(c *gin.Context) {
+	c.JSON(http.StatusOK, i.cacheAdminService.ListKeys(c.Request.Context()))
+}
+
+func (i *Implementation) GetCacheEntry(c *gin.Context) {
+	entry, err := i.cacheAdminService.GetEntry(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+func (i *Implementation) EvictCacheKey(c *gin.Context) {
+	i.cacheAdminService.Evict(c.Request.Context(), c.Param("key"))
+	c.Status(http.StatusNoContent)
+}
+
+func (i *Implementation) FlushCache(c *gin.Context) {
+	i.cacheAdminService.Flush(c.Request.Context())
+	c.Status(http.StatusNoContent)
+}
+
+func (i *Implementation) GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, i.cacheAdminService.Stats(c.Request.Context()))
+}
+
+func (i *Implementation) GetWarmUpProgress(c *gin.Context) {
+	c.JSON(http.StatusOK, i.warmUpService.Progress(c.Request.Context()))
+}
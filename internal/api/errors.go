@@ -0,0 +1,75 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
+	"github.com/gin-gonic/gin"
+)
+
+// statusClientClosedRequest is the nginx status for a request the client
+// abandoned; nobody reads the response, but logs and metrics do.
+const statusClientClosedRequest = 499
+
+var statusByCode = map[errs.Code]int{
+	errs.CodeNotFound:         http.StatusNotFound,
+	errs.CodeInvalidArgument:  http.StatusBadRequest,
+	errs.CodeConflict:         http.StatusConflict,
+	errs.CodeUnauthenticated:  http.StatusUnauthorized,
+	errs.CodeUnavailable:      http.StatusServiceUnavailable,
+	errs.CodeCanceled:         statusClientClosedRequest,
+	errs.CodeDeadlineExceeded: http.StatusGatewayTimeout,
+}
+
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    errs.Code `json:"code"`
+	Message string    `json:"message"`
+}
+
+// ErrorHandler writes the last error a handler added with c.Error, with the
+// status of its code.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		status, response := newErrorResponse(c, c.Errors.Last().Err)
+		c.JSON(status, response)
+	}
+}
+
+// newErrorResponse picks the status and body for err. Unavailable, deadline
+// and internal errors are logged, and their details are not shown to
+// clients; neither are those of canceled requests.
+func newErrorResponse(c *gin.Context, err error) (int, errorResponse) {
+	code := errs.CodeOf(err)
+	status, ok := statusByCode[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	message := err.Error()
+	switch code {
+	case errs.CodeUnavailable:
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		message = "service unavailable"
+	case errs.CodeDeadlineExceeded:
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		message = "deadline exceeded"
+	case errs.CodeCanceled:
+		message = "request canceled"
+	case errs.CodeInternal:
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		message = "internal error"
+	}
+
+	return status, errorResponse{Error: errorBody{Code: code, Message: message}}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveError(err error) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/", func(c *gin.Context) {
+		c.Error(err)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w
+}
+
+func TestErrorHandler_MapsCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		body   string
+	}{
+		{
+			name:   "not found",
+			err:    fmt.Errorf("failed to get order: %w", repository.ErrOrderNotFound),
+			status: http.StatusNotFound,
+			body:   `{"error":{"code":"not_found","message":"failed to get order: order not found"}}`,
+		},
+		{
+			name:   "invalid argument",
+			err:    errs.InvalidArgument("invalid limit: abc"),
+			status: http.StatusBadRequest,
+			body:   `{"error":{"code":"invalid_argument","message":"invalid limit: abc"}}`,
+		},
+		{
+			name:   "conflict",
+			err:    errs.Conflict("order uid1 already exists"),
+			status: http.StatusConflict,
+			body:   `{"error":{"code":"conflict","message":"order uid1 already exists"}}`,
+		},
+		{
+			name:   "unavailable",
+			err:    errs.Wrap(errs.CodeUnavailable, "failed to query order", errors.New("dial tcp: connection refused")),
+			status: http.StatusServiceUnavailable,
+			body:   `{"error":{"code":"unavailable","message":"service unavailable"}}`,
+		},
+		{
+			name:   "deadline exceeded",
+			err:    errs.Wrap(errs.CodeDeadlineExceeded, "failed to query order", context.DeadlineExceeded),
+			status: http.StatusGatewayTimeout,
+			body:   `{"error":{"code":"deadline_exceeded","message":"deadline exceeded"}}`,
+		},
+		{
+			name:   "canceled",
+			err:    errs.Wrap(errs.CodeCanceled, "failed to query order", context.Canceled),
+			status: statusClientClosedRequest,
+			body:   `{"error":{"code":"canceled","message":"request canceled"}}`,
+		},
+		{
+			name:   "internal",
+			err:    errors.New("unexpected"),
+			status: http.StatusInternalServerError,
+			body:   `{"error":{"code":"internal","message":"internal error"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveError(tt.err)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.JSONEq(t, tt.body, w.Body.String())
+		})
+	}
+}
+
+func TestErrorHandler_KeepsWrittenResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		c.Error(errors.New("stream failed"))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "partial", w.Body.String())
+}
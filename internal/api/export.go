@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportOrders streams every order as NDJSON. A failure before the first
+// order is written gets an error status like any other request. Once the
+// stream has started the status is already sent, so a failure ends it with
+// one more line in the shape of an error response, {"error":{...}}, which
+// clients must check the last line for.
+func (i *Implementation) ExportOrders(c *gin.Context) {
+	w := &exportWriter{c: c}
+	exported, err := i.exportService.Export(c.Request.Context(), w)
+	if err == nil {
+		w.start()
+		return
+	}
+	if !w.started {
+		c.Error(err)
+		return
+	}
+
+	log.Printf("failed to export orders after %d orders: %v", exported, err)
+	_, response := newErrorResponse(c, err)
+	err = json.NewEncoder(c.Writer).Encode(response)
+	if err != nil {
+		log.Printf("failed to write export error: %v", err)
+	}
+}
+
+// exportWriter sends the headers of the export with its first write, which
+// comes after the first chunk of orders was read.
+type exportWriter struct {
+	c       *gin.Context
+	started bool
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	w.start()
+	return w.c.Writer.Write(p)
+}
+
+func (w *exportWriter) start() {
+	if w.started {
+		return
+	}
+	w.started = true
+
+	w.c.Header("Content-Type", "application/x-ndjson")
+	w.c.Header("Content-Disposition", `attachment; filename="orders.ndjson"`)
+	w.c.Status(http.StatusOK)
+}
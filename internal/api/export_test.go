@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
+	"github.com/biryanim/wb_tech_L0/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExportService struct {
+	service.ExportService
+	lines []string
+	err   error
+}
+
+func (s *fakeExportService) Export(ctx context.Context, w io.Writer) (int, error) {
+	for i, line := range s.lines {
+		_, err := io.WriteString(w, line+"\n")
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(s.lines), s.err
+}
+
+func serveExport(s *fakeExportService) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/export", NewImplementation(nil, nil, nil, s).ExportOrders)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+	return w
+}
+
+func TestExportOrders_Streams(t *testing.T) {
+	w := serveExport(&fakeExportService{lines: []string{`{"order_uid":"uid1"}`}})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Equal(t, "{\"order_uid\":\"uid1\"}\n", w.Body.String())
+}
+
+func TestExportOrders_EmptyExport(t *testing.T) {
+	w := serveExport(&fakeExportService{})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Body.String())
+}
+
+func TestExportOrders_ReportsEarlyFailureWithStatus(t *testing.T) {
+	w := serveExport(&fakeExportService{err: errs.Wrap(errs.CodeUnavailable, "failed to list order ids", errors.New("connection refused"))})
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"error":{"code":"unavailable","message":"service unavailable"}}`, w.Body.String())
+}
+
+func TestExportOrders_EndsFailedStreamWithErrorLine(t *testing.T) {
+	w := serveExport(&fakeExportService{lines: []string{`{"order_uid":"uid1"}`}, err: errors.New("boom")})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"error":{"code":"internal","message":"internal error"}}`, lines[1])
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
+	"github.com/gin-gonic/gin"
+)
+
+func (i *Implementation) GetOrderHistory(c *gin.Context) {
+	versions, err := i.orderService.ListVersions(c.Request.Context(), c.Param("order_uid"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+func (i *Implementation) getOrderAsOf(c *gin.Context, orderUID string, asOf string) {
+	t, err := time.Parse(time.RFC3339, asOf)
+	if err != nil {
+		c.Error(errs.InvalidArgument("invalid as_of: " + asOf))
+		return
+	}
+
+	order, err := i.orderService.GetOrderAsOf(c.Request.Context(), orderUID, t)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func (i *Implementation) ListOrders(c *gin.Context) {
+	filter, err := orderFilterFromQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, err := i.orderService.ListOrders(c.Request.Context(), filter, c.Query("cursor"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (i *Implementation) SearchOrders(c *gin.Context) {
+	query := &model.OrderSearchQuery{Query: c.Query("q")}
+
+	var err error
+	query.Limit, err = intFromQuery(c, "limit")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	query.Offset, err = intFromQuery(c, "offset")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, err := i.orderService.Search(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func orderFilterFromQuery(c *gin.Context) (*model.OrderFilter, error) {
+	filter := &model.OrderFilter{
+		CustomerID:      c.Query("customer_id"),
+		TrackNumber:     c.Query("track_number"),
+		DeliveryService: c.Query("delivery_service"),
+		Locale:          c.Query("locale"),
+		Bank:            c.Query("bank"),
+		Provider:        c.Query("provider"),
+		Brand:           c.Query("brand"),
+	}
+
+	var err error
+	filter.Limit, err = intFromQuery(c, "limit")
+	if err != nil {
+		return nil, err
+	}
+	filter.CreatedFrom, err = timeFromQuery(c, "from")
+	if err != nil {
+		return nil, err
+	}
+	filter.CreatedTo, err = timeFromQuery(c, "to")
+	if err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+func intFromQuery(c *gin.Context, key string) (int, error) {
+	value := c.Query(key)
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, errs.InvalidArgument(fmt.Sprintf("invalid %s: %s", key, value))
+	}
+
+	return n, nil
+}
+
+func timeFromQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errs.InvalidArgument(fmt.Sprintf("invalid %s: %s", key, value))
+	}
+
+	return &t, nil
+}
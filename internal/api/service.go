@@ -4,26 +4,76 @@ import (
 	"github.com/biryanim/wb_tech_L0/internal/service"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strings"
 )
 
+const staleHeader = "X-Cache-Stale"
+
 type Implementation struct {
-	orderService service.OrderService
+	orderService      service.OrderService
+	cacheAdminService service.CacheAdminService
+	warmUpService     service.WarmUpService
+	exportService     service.ExportService
 	//consumerService service.ConsumerService
 }
 
-func NewImplementation(orderService service.OrderService) *Implementation {
+func NewImplementation(
+	orderService service.OrderService,
+	cacheAdminService service.CacheAdminService,
+	warmUpService service.WarmUpService,
+	exportService service.ExportService,
+) *Implementation {
 	return &Implementation{
-		orderService: orderService,
+		orderService:      orderService,
+		cacheAdminService: cacheAdminService,
+		warmUpService:     warmUpService,
+		exportService:     exportService,
 	}
 }
 
 func (i *Implementation) GetOrder(c *gin.Context) {
 	orderUID := c.Param("order_uid")
 
-	order, err := i.orderService.GetOrder(c.Request.Context(), orderUID)
+	if asOf := c.Query("as_of"); len(asOf) != 0 {
+		i.getOrderAsOf(c, orderUID, asOf)
+		return
+	}
+
+	snapshot, stale, err := i.orderService.GetOrderSnapshot(c.Request.Context(), orderUID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	if stale {
+		c.Header(staleHeader, "true")
+	}
+	c.Header("ETag", snapshot.ETag())
+	if etagMatches(c.GetHeader("If-None-Match"), snapshot.ETag()) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+	snapshot.WriteTo(c.Writer)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. Tags are
+// compared weakly, as RFC 9110 requires for If-None-Match.
+func etagMatches(header string, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if len(tag) != 0 && tag == etag {
+			return true
+		}
 	}
 
-	c.JSON(http.StatusOK, order)
+	return false
 }
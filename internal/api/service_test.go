@@ -0,0 +1,33 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEtagMatches(t *testing.T) {
+	const etag = `"abc"`
+
+	tests := []struct {
+		name   string
+		header string
+		match  bool
+	}{
+		{name: "empty", header: "", match: false},
+		{name: "same", header: `"abc"`, match: true},
+		{name: "other", header: `"abd"`, match: false},
+		{name: "any", header: "*", match: true},
+		{name: "list", header: `"x", "abc"`, match: true},
+		{name: "list without match", header: `"x","y"`, match: false},
+		{name: "weak", header: `W/"abc"`, match: true},
+		{name: "weak in list", header: `"x", W/"abc"`, match: true},
+		{name: "unquoted", header: "abc", match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.match, etagMatches(tt.header, etag))
+		})
+	}
+}
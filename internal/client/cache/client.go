@@ -1,7 +1,61 @@
 package cache
 
+import (
+	"context"
+	"time"
+)
+
+type Loader func(ctx context.Context, key string) (interface{}, error)
+
+type Listener func(key string, value interface{})
+
+// Entry describes a cached key; Age is the time since the value was set.
+type Entry struct {
+	Key string
+	Age time.Duration
+}
+
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
 type Client interface {
 	Set(key string, value interface{}) bool
 	Get(key string) interface{}
 	Remove(key string) bool
+
+	// Peek returns the value without touching recency or hit counters.
+	Peek(key string) interface{}
+	// Keys lists cached keys, most recently used first.
+	Keys() []Entry
+	Flush()
+	Stats() Stats
+}
+
+// GuardedClient lets a loader that read the source before a concurrent
+// Set or Remove skip caching what it read, since the value may already be
+// outdated.
+type GuardedClient interface {
+	Client
+	// Generation returns a token that changes whenever key is set or removed
+	// or the cache is flushed.
+	Generation(key string) uint64
+	// SetIfUnchanged sets key only if its generation is still gen.
+	SetIfUnchanged(key string, value interface{}, gen uint64) bool
+}
+
+type ReadThroughClient interface {
+	Client
+	GetOrLoad(ctx context.Context, key string, loader Loader) (interface{}, error)
+}
+
+// Observable clients call registered listeners outside of their internal
+// locks, so a listener may safely use the cache it is registered on.
+type Observable interface {
+	OnSet(listener Listener)
+	OnEvict(listener Listener)
+	OnExpire(listener Listener)
 }
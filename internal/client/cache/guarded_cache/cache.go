@@ -0,0 +1,101 @@
+package guarded_cache
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/cache"
+)
+
+const stripes = 256
+
+var (
+	_ cache.GuardedClient     = (*Cache)(nil)
+	_ cache.ReadThroughClient = (*ReadThroughCache)(nil)
+)
+
+// Cache counts sets and removals of the keys of the wrapped client. Keys
+// share a fixed number of counters, so changing one key may also change the
+// generation of another; SetIfUnchanged then skips a set that would have been
+// safe.
+type Cache struct {
+	cache.Client
+
+	seed        maphash.Seed
+	mutex       sync.Mutex
+	generations [stripes]uint64
+}
+
+func New(client cache.Client) *Cache {
+	return &Cache{
+		Client: client,
+		seed:   maphash.MakeSeed(),
+	}
+}
+
+func (c *Cache) Set(key string, value interface{}) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generations[c.stripe(key)]++
+	return c.Client.Set(key, value)
+}
+
+func (c *Cache) Remove(key string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generations[c.stripe(key)]++
+	return c.Client.Remove(key)
+}
+
+func (c *Cache) Flush() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i := range c.generations {
+		c.generations[i]++
+	}
+	c.Client.Flush()
+}
+
+func (c *Cache) Generation(key string) uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generations[c.stripe(key)]
+}
+
+func (c *Cache) SetIfUnchanged(key string, value interface{}, gen uint64) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.generations[c.stripe(key)] != gen {
+		return false
+	}
+
+	return c.Client.Set(key, value)
+}
+
+func (c *Cache) stripe(key string) uint64 {
+	return maphash.String(c.seed, key) % stripes
+}
+
+// ReadThroughCache is a Cache over a read-through client. Values loaded by
+// GetOrLoad are set by the wrapped client and do not change generations.
+type ReadThroughCache struct {
+	*Cache
+	client cache.ReadThroughClient
+}
+
+func NewReadThrough(client cache.ReadThroughClient) *ReadThroughCache {
+	return &ReadThroughCache{
+		Cache:  New(client),
+		client: client,
+	}
+}
+
+func (c *ReadThroughCache) GetOrLoad(ctx context.Context, key string, loader cache.Loader) (interface{}, error) {
+	return c.client.GetOrLoad(ctx, key, loader)
+}
@@ -0,0 +1,47 @@
+package guarded_cache
+
+import (
+	"testing"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/lru_cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuarded_SetIfUnchanged(t *testing.T) {
+	c := New(lru_cache.New(3))
+	gen := c.Generation("uid1")
+
+	assert.True(t, c.SetIfUnchanged("uid1", true, gen))
+	assert.Equal(t, true, c.Get("uid1"))
+}
+
+func TestGuarded_SetIfUnchangedAfterRemove(t *testing.T) {
+	c := New(lru_cache.New(3))
+	gen := c.Generation("uid1")
+
+	c.Remove("uid1")
+
+	assert.False(t, c.SetIfUnchanged("uid1", true, gen))
+	assert.Nil(t, c.Get("uid1"))
+	assert.True(t, c.SetIfUnchanged("uid1", true, c.Generation("uid1")))
+}
+
+func TestGuarded_SetIfUnchangedAfterSet(t *testing.T) {
+	c := New(lru_cache.New(3))
+	gen := c.Generation("uid1")
+
+	c.Set("uid1", "fresh")
+
+	assert.False(t, c.SetIfUnchanged("uid1", "stale", gen))
+	assert.Equal(t, "fresh", c.Get("uid1"))
+}
+
+func TestGuarded_SetIfUnchangedAfterFlush(t *testing.T) {
+	c := New(lru_cache.New(3))
+	gen := c.Generation("uid1")
+
+	c.Flush()
+
+	assert.False(t, c.SetIfUnchanged("uid1", true, gen))
+	assert.Nil(t, c.Get("uid1"))
+}
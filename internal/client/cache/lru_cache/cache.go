@@ -4,44 +4,188 @@ import (
 	"container/list"
 	"github.com/biryanim/wb_tech_L0/internal/client/cache"
 	"sync"
+	"time"
 )
 
-var _ cache.Client = (*Cache)(nil)
+var (
+	_ cache.Client     = (*Cache)(nil)
+	_ cache.Observable = (*Cache)(nil)
+)
 
 type Item struct {
 	Key   string
 	Value interface{}
 }
 
+type meta struct {
+	setAt     time.Time
+	expiresAt time.Time
+}
+
+type event struct {
+	listeners []cache.Listener
+	item      *Item
+}
+
 type Cache struct {
 	capacity int
+	ttl      time.Duration
 	queue    *list.List
 	mutex    *sync.RWMutex
 	items    map[string]*list.Element
+	meta     map[string]meta
+	now      func() time.Time
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+
+	onSet    []cache.Listener
+	onEvict  []cache.Listener
+	onExpire []cache.Listener
 }
 
 func New(capacity int) *Cache {
+	return NewWithTTL(capacity, 0)
+}
+
+// NewWithTTL creates a cache whose entries expire ttl after they were set.
+// A zero ttl disables expiry.
+func NewWithTTL(capacity int, ttl time.Duration) *Cache {
 	return &Cache{
 		capacity: capacity,
+		ttl:      ttl,
 		queue:    list.New(),
 		mutex:    new(sync.RWMutex),
 		items:    make(map[string]*list.Element),
+		meta:     make(map[string]meta),
+		now:      time.Now,
 	}
 }
 
 func (c *Cache) Set(key string, value interface{}) bool {
+	c.mutex.Lock()
+	events := c.set(key, value)
+	c.mutex.Unlock()
+
+	notify(events)
+	return true
+}
+
+func (c *Cache) Get(key string) interface{} {
+	c.mutex.Lock()
+	value, events := c.get(key)
+	c.mutex.Unlock()
+
+	notify(events)
+	return value
+}
+
+func (c *Cache) Remove(key string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if val, found := c.items[key]; found {
+		c.deleteItem(val)
+	}
+
+	return true
+}
+
+func (c *Cache) Peek(key string) interface{} {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	element, exists := c.items[key]
+	if !exists || c.expired(key) {
+		return nil
+	}
+
+	return element.Value.(*Item).Value
+}
+
+func (c *Cache) Keys() []cache.Entry {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	now := c.now()
+	entries := make([]cache.Entry, 0, c.queue.Len())
+	for element := c.queue.Front(); element != nil; element = element.Next() {
+		key := element.Value.(*Item).Key
+		if c.expired(key) {
+			continue
+		}
+
+		entries = append(entries, cache.Entry{
+			Key: key,
+			Age: now.Sub(c.meta[key].setAt),
+		})
+	}
+
+	return entries
+}
+
+func (c *Cache) Flush() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.queue.Init()
+	c.items = make(map[string]*list.Element)
+	c.meta = make(map[string]meta)
+}
+
+func (c *Cache) Stats() cache.Stats {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return cache.Stats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Size:      c.queue.Len(),
+	}
+}
+
+func (c *Cache) OnSet(listener cache.Listener) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
+	c.onSet = append(c.onSet, listener)
+}
+
+func (c *Cache) OnEvict(listener cache.Listener) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.onEvict = append(c.onEvict, listener)
+}
+
+func (c *Cache) OnExpire(listener cache.Listener) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.onExpire = append(c.onExpire, listener)
+}
+
+func (c *Cache) set(key string, value interface{}) []event {
+	now := c.now()
+	m := meta{setAt: now}
+	if c.ttl > 0 {
+		m.expiresAt = now.Add(c.ttl)
+	}
+
 	if element, exists := c.items[key]; exists {
+		c.meta[key] = m
 		c.queue.MoveToFront(element)
-		element.Value.(*Item).Value = value
-		return true
+		item := element.Value.(*Item)
+		item.Value = value
+		return c.events(nil, c.onSet, item)
 	}
 
+	var events []event
 	if c.queue.Len() == c.capacity {
-		c.clear()
+		events = c.clear()
 	}
+	c.meta[key] = m
 
 	item := &Item{
 		Key:   key,
@@ -51,39 +195,71 @@ func (c *Cache) Set(key string, value interface{}) bool {
 	element := c.queue.PushFront(item)
 	c.items[item.Key] = element
 
-	return true
+	return c.events(events, c.onSet, item)
 }
 
-func (c *Cache) Get(key string) interface{} {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-
+func (c *Cache) get(key string) (interface{}, []event) {
 	element, exists := c.items[key]
 	if exists == false {
-		return nil
+		c.misses++
+		return nil, nil
+	}
+
+	item := element.Value.(*Item)
+	if c.expired(key) {
+		c.deleteItem(element)
+		c.misses++
+		return nil, c.events(nil, c.onExpire, item)
 	}
 
+	c.hits++
 	c.queue.MoveToFront(element)
-	return element.Value.(*Item).Value
+	return item.Value, nil
 }
 
-func (c *Cache) Remove(key string) bool {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	if val, found := c.items[key]; found {
-		c.deleteItem(val)
+func (c *Cache) clear() []event {
+	element := c.queue.Back()
+	if element == nil {
+		return nil
 	}
 
-	return true
+	item := element.Value.(*Item)
+	expired := c.expired(item.Key)
+	c.deleteItem(element)
+	if expired {
+		return c.events(nil, c.onExpire, item)
+	}
+
+	c.evictions++
+	return c.events(nil, c.onEvict, item)
 }
 
-func (c *Cache) clear() {
-	if element := c.queue.Back(); element != nil {
-		c.deleteItem(element)
+func (c *Cache) events(events []event, listeners []cache.Listener, item *Item) []event {
+	if len(listeners) == 0 {
+		return events
 	}
+
+	return append(events, event{
+		listeners: listeners,
+		item:      &Item{Key: item.Key, Value: item.Value},
+	})
+}
+
+func notify(events []event) {
+	for _, e := range events {
+		for _, listener := range e.listeners {
+			listener(e.item.Key, e.item.Value)
+		}
+	}
+}
+
+func (c *Cache) expired(key string) bool {
+	expiresAt := c.meta[key].expiresAt
+	return !expiresAt.IsZero() && !c.now().Before(expiresAt)
 }
 
 func (c *Cache) deleteItem(element *list.Element) {
 	item := c.queue.Remove(element).(*Item)
 	delete(c.items, item.Key)
+	delete(c.meta, item.Key)
 }
@@ -1,9 +1,11 @@
 package lru_cache
 
 import (
+	"github.com/biryanim/wb_tech_L0/internal/client/cache"
 	"github.com/stretchr/testify/assert"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestLRU_SetExistingElementToFullCache(t *testing.T) {
@@ -171,3 +173,107 @@ func TestLRU_RemoveHasNotElement(t *testing.T) {
 	assert.Equal(t, 8, backItem.Value)
 	assert.Equal(t, 3, lru.queue.Len())
 }
+
+func TestLRU_GetExpiredElement(t *testing.T) {
+	now := time.Now()
+	lru := NewWithTTL(3, time.Minute)
+	lru.now = func() time.Time { return now }
+	lru.Set("someKey1", 8)
+	lru.Set("someKey2", 3)
+
+	now = now.Add(30 * time.Second)
+	lru.Set("someKey2", 4)
+	now = now.Add(30 * time.Second)
+
+	assert.Nil(t, lru.Get("someKey1"))
+	assert.Equal(t, 4, lru.Get("someKey2"))
+	assert.Equal(t, 1, lru.queue.Len())
+}
+
+func TestLRU_PeekKeepsOrder(t *testing.T) {
+	lru := New(3)
+	lru.Set("someKey1", 8)
+	lru.Set("someKey2", 3)
+
+	item := lru.Peek("someKey1")
+
+	frontItem := lru.queue.Front().Value.(*Item)
+	assert.Equal(t, 8, item)
+	assert.Equal(t, "someKey2", frontItem.Key)
+	assert.Equal(t, uint64(0), lru.Stats().Hits)
+}
+
+func TestLRU_KeysInRecencyOrder(t *testing.T) {
+	now := time.Now()
+	lru := New(3)
+	lru.now = func() time.Time { return now }
+	lru.Set("someKey1", 8)
+	now = now.Add(time.Second)
+	lru.Set("someKey2", 3)
+	lru.Get("someKey1")
+
+	keys := lru.Keys()
+
+	assert.Equal(t, []cache.Entry{
+		{Key: "someKey1", Age: time.Second},
+		{Key: "someKey2", Age: 0},
+	}, keys)
+}
+
+func TestLRU_Stats(t *testing.T) {
+	lru := New(2)
+	lru.Set("someKey1", 8)
+	lru.Set("someKey2", 3)
+	lru.Set("someKey3", 0)
+	lru.Get("someKey3")
+	lru.Get("someKey1")
+
+	assert.Equal(t, cache.Stats{Hits: 1, Misses: 1, Evictions: 1, Size: 2}, lru.Stats())
+}
+
+func TestLRU_Flush(t *testing.T) {
+	lru := New(3)
+	lru.Set("someKey1", 8)
+	lru.Set("someKey2", 3)
+
+	lru.Flush()
+
+	assert.Nil(t, lru.Get("someKey1"))
+	assert.Empty(t, lru.Keys())
+	assert.Equal(t, 0, lru.queue.Len())
+}
+
+func TestLRU_Listeners(t *testing.T) {
+	now := time.Now()
+	lru := NewWithTTL(2, time.Minute)
+	lru.now = func() time.Time { return now }
+	var set, evicted, expired []string
+	lru.OnSet(func(key string, value interface{}) { set = append(set, key) })
+	lru.OnEvict(func(key string, value interface{}) { evicted = append(evicted, key) })
+	lru.OnExpire(func(key string, value interface{}) { expired = append(expired, key) })
+
+	lru.Set("someKey1", 8)
+	lru.Set("someKey2", 3)
+	lru.Set("someKey3", 0)
+	now = now.Add(2 * time.Minute)
+	lru.Get("someKey2")
+
+	assert.Equal(t, []string{"someKey1", "someKey2", "someKey3"}, set)
+	assert.Equal(t, []string{"someKey1"}, evicted)
+	assert.Equal(t, []string{"someKey2"}, expired)
+}
+
+func TestLRU_ListenerCanUseCache(t *testing.T) {
+	lru := New(1)
+	evicted := New(1)
+	lru.OnEvict(func(key string, value interface{}) {
+		evicted.Set(key, value)
+		lru.Get(key)
+	})
+
+	lru.Set("someKey1", 8)
+	lru.Set("someKey2", 3)
+
+	assert.Equal(t, 8, evicted.Get("someKey1"))
+	assert.Equal(t, 3, lru.Get("someKey2"))
+}
@@ -0,0 +1,105 @@
+package lru_cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrSnapshotStale = errors.New("cache snapshot is stale")
+
+type Decoder func(data []byte) (interface{}, error)
+
+type snapshot struct {
+	CreatedAt time.Time       `json:"created_at"`
+	Entries   []snapshotEntry `json:"entries"`
+}
+
+type snapshotEntry struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// WriteSnapshot stores the cache content in recency order, most recently
+// used first. The file is replaced atomically.
+func (c *Cache) WriteSnapshot(path string) error {
+	c.mutex.RLock()
+	snap := snapshot{
+		CreatedAt: c.now(),
+		Entries:   make([]snapshotEntry, 0, c.queue.Len()),
+	}
+	for element := c.queue.Front(); element != nil; element = element.Next() {
+		item := element.Value.(*Item)
+		if c.expired(item.Key) {
+			continue
+		}
+
+		value, err := json.Marshal(item.Value)
+		if err != nil {
+			c.mutex.RUnlock()
+			return errors.Wrapf(err, "failed to marshal cache entry %s", item.Key)
+		}
+		snap.Entries = append(snap.Entries, snapshotEntry{Key: item.Key, Value: value})
+	}
+	c.mutex.RUnlock()
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal cache snapshot")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create cache snapshot file")
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write cache snapshot")
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// ReadSnapshot loads a snapshot written by WriteSnapshot, keeping its
+// recency order. Snapshots older than maxAge are rejected with
+// ErrSnapshotStale; a zero maxAge accepts any age.
+func (c *Cache) ReadSnapshot(path string, maxAge time.Duration, decode Decoder) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var snap snapshot
+	err = json.Unmarshal(data, &snap)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to unmarshal cache snapshot")
+	}
+
+	if maxAge > 0 && c.now().Sub(snap.CreatedAt) > maxAge {
+		return 0, ErrSnapshotStale
+	}
+
+	values := make([]interface{}, len(snap.Entries))
+	for i, entry := range snap.Entries {
+		values[i], err = decode(entry.Value)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to decode cache entry %s", entry.Key)
+		}
+	}
+
+	restored := 0
+	for i := len(snap.Entries) - 1; i >= 0; i-- {
+		c.Set(snap.Entries[i].Key, values[i])
+		restored++
+	}
+
+	return restored, nil
+}
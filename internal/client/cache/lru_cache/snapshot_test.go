@@ -0,0 +1,67 @@
+package lru_cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeInt(data []byte) (interface{}, error) {
+	var value int
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+func TestLRU_SnapshotKeepsRecencyOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	lru := New(3)
+	lru.Set("someKey1", 1)
+	lru.Set("someKey2", 2)
+	lru.Set("someKey3", 3)
+	lru.Get("someKey1")
+
+	require.NoError(t, lru.WriteSnapshot(path))
+
+	restored := New(3)
+	n, err := restored.ReadSnapshot(path, time.Minute, decodeInt)
+	require.NoError(t, err)
+
+	frontItem := restored.queue.Front().Value.(*Item)
+	backItem := restored.queue.Back().Value.(*Item)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "someKey1", frontItem.Key)
+	assert.Equal(t, 1, frontItem.Value)
+	assert.Equal(t, "someKey2", backItem.Key)
+	assert.Equal(t, 2, backItem.Value)
+	assert.Equal(t, 3, restored.queue.Len())
+}
+
+func TestLRU_SnapshotStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	now := time.Now()
+	lru := New(3)
+	lru.now = func() time.Time { return now }
+	lru.Set("someKey1", 1)
+	require.NoError(t, lru.WriteSnapshot(path))
+
+	restored := New(3)
+	restored.now = func() time.Time { return now.Add(2 * time.Minute) }
+	n, err := restored.ReadSnapshot(path, time.Minute, decodeInt)
+
+	assert.ErrorIs(t, err, ErrSnapshotStale)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 0, restored.queue.Len())
+}
+
+func TestLRU_SnapshotMissing(t *testing.T) {
+	lru := New(3)
+
+	_, err := lru.ReadSnapshot(filepath.Join(t.TempDir(), "cache.json"), time.Minute, decodeInt)
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,215 @@
+package redis_cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/cache"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+var _ cache.Client = (*Cache)(nil)
+
+const opTimeout = time.Second
+
+// Decoder turns the JSON stored under a key back into the cached value.
+type Decoder func(data []byte) (interface{}, error)
+
+// DecodeJSON returns a Decoder that unmarshals values into a new *T.
+func DecodeJSON[T any]() Decoder {
+	return func(data []byte) (interface{}, error) {
+		value := new(T)
+		err := json.Unmarshal(data, value)
+		if err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+type Cache struct {
+	client redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+	decode Decoder
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// New stores values as JSON under prefix; decode reads them back.
+func New(client redis.UniversalClient, prefix string, ttl time.Duration, decode Decoder) *Cache {
+	return &Cache{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+		decode: decode,
+	}
+}
+
+func (c *Cache) Set(key string, value interface{}) bool {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("redis cache: failed to marshal value for key %s: %v", key, err)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	err = c.client.Set(ctx, c.key(key), data, c.ttl).Err()
+	if err != nil {
+		log.Printf("redis cache: failed to set key %s: %v", key, err)
+		return false
+	}
+
+	return true
+}
+
+func (c *Cache) Get(key string) interface{} {
+	value := c.Peek(key)
+	if value == nil {
+		c.misses.Add(1)
+		return nil
+	}
+
+	c.hits.Add(1)
+	return value
+}
+
+func (c *Cache) Peek(key string) interface{} {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.key(key)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("redis cache: failed to get key %s: %v", key, err)
+		}
+		return nil
+	}
+
+	value, err := c.decode(data)
+	if err != nil {
+		log.Printf("redis cache: failed to unmarshal value for key %s: %v", key, err)
+		return nil
+	}
+
+	return value
+}
+
+func (c *Cache) Remove(key string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	err := c.client.Del(ctx, c.key(key)).Err()
+	if err != nil {
+		log.Printf("redis cache: failed to remove key %s: %v", key, err)
+		return false
+	}
+
+	return true
+}
+
+// Keys orders entries by the time they were last written, since Redis does
+// not expose read recency per key. Entry age is derived from the remaining
+// TTL and is zero when no TTL is configured.
+func (c *Cache) Keys() []cache.Entry {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	keys, err := c.scan(ctx)
+	if err != nil {
+		log.Printf("redis cache: failed to list keys: %v", err)
+		return nil
+	}
+
+	pipe := c.client.Pipeline()
+	ttls := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		ttls[i] = pipe.TTL(ctx, key)
+	}
+	_, err = pipe.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Printf("redis cache: failed to get key ttls: %v", err)
+		return nil
+	}
+
+	entries := make([]cache.Entry, 0, len(keys))
+	for i, key := range keys {
+		remaining, err := ttls[i].Result()
+		if err != nil || remaining == -2 {
+			continue
+		}
+
+		var age time.Duration
+		if c.ttl > 0 && remaining > 0 {
+			age = c.ttl - remaining
+		}
+		entries = append(entries, cache.Entry{
+			Key: key[len(c.prefix):],
+			Age: age,
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Age < entries[j].Age
+	})
+
+	return entries
+}
+
+func (c *Cache) Flush() {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	keys, err := c.scan(ctx)
+	if err != nil {
+		log.Printf("redis cache: failed to list keys: %v", err)
+		return
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	err = c.client.Del(ctx, keys...).Err()
+	if err != nil {
+		log.Printf("redis cache: failed to flush: %v", err)
+	}
+}
+
+// Stats reports hits and misses seen by this client only; evictions are
+// done by Redis itself and are not tracked.
+func (c *Cache) Stats() cache.Stats {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	keys, err := c.scan(ctx)
+	if err != nil {
+		log.Printf("redis cache: failed to list keys: %v", err)
+	}
+
+	return cache.Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Size:   len(keys),
+	}
+}
+
+func (c *Cache) scan(ctx context.Context) ([]string, error) {
+	var keys []string
+	iter := c.client.Scan(ctx, 0, c.prefix+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+
+	return keys, iter.Err()
+}
+
+func (c *Cache) key(key string) string {
+	return c.prefix + key
+}
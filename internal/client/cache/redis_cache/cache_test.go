@@ -0,0 +1,145 @@
+package redis_cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/biryanim/wb_tech_L0/internal/client/cache"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCache(t *testing.T, ttl time.Duration) (*Cache, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return New(client, "order:", ttl, DecodeJSON[model.OrderSnapshot]()), mr
+}
+
+func testOrder(uid string) *model.OrderSnapshot {
+	snapshot, _ := model.NewOrderSnapshot(&model.Order{
+		OrderUID:    uid,
+		TrackNumber: "TRACK1",
+		Delivery:    model.Delivery{Name: "Ivan Petrov", City: "Moscow"},
+		Payment:     model.Payment{Transaction: uid, Currency: "USD", Amount: model.NewMoney(181750, "USD")},
+		Items:       []model.Item{{ChrtID: 9934930, Name: "Mascaras", Price: model.NewMoney(45300, "USD")}},
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+	})
+	return snapshot
+}
+
+func TestRedis_SetAndGet(t *testing.T) {
+	c, _ := newTestCache(t, time.Minute)
+	order := testOrder("uid1")
+
+	assert.True(t, c.Set("uid1", order))
+
+	cached, ok := c.Get("uid1").(*model.OrderSnapshot)
+	require.True(t, ok)
+	assert.Equal(t, order.ETag(), cached.ETag())
+
+	expected, err := order.Order()
+	require.NoError(t, err)
+	actual, err := cached.Order()
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestRedis_DecodesOtherValueTypes(t *testing.T) {
+	type reads struct {
+		Count int `json:"count"`
+	}
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	c := New(client, "reads:", time.Minute, DecodeJSON[reads]())
+
+	assert.True(t, c.Set("uid1", reads{Count: 3}))
+	assert.Equal(t, &reads{Count: 3}, c.Get("uid1"))
+}
+
+func TestRedis_GetHasNotElement(t *testing.T) {
+	c, _ := newTestCache(t, time.Minute)
+
+	assert.Nil(t, c.Get("uid1"))
+}
+
+func TestRedis_SetUsesPrefixAndTTL(t *testing.T) {
+	c, mr := newTestCache(t, time.Minute)
+
+	c.Set("uid1", testOrder("uid1"))
+
+	assert.True(t, mr.Exists("order:uid1"))
+	assert.Equal(t, time.Minute, mr.TTL("order:uid1"))
+}
+
+func TestRedis_ElementExpires(t *testing.T) {
+	c, mr := newTestCache(t, time.Minute)
+	c.Set("uid1", testOrder("uid1"))
+
+	mr.FastForward(2 * time.Minute)
+
+	assert.Nil(t, c.Get("uid1"))
+}
+
+func TestRedis_Remove(t *testing.T) {
+	c, _ := newTestCache(t, time.Minute)
+	c.Set("uid1", testOrder("uid1"))
+
+	assert.True(t, c.Remove("uid1"))
+	assert.Nil(t, c.Get("uid1"))
+	assert.True(t, c.Remove("uid1"))
+}
+
+func TestRedis_GetUnavailable(t *testing.T) {
+	c, mr := newTestCache(t, time.Minute)
+	c.Set("uid1", testOrder("uid1"))
+
+	mr.Close()
+
+	assert.Nil(t, c.Get("uid1"))
+	assert.False(t, c.Set("uid1", testOrder("uid1")))
+}
+
+func TestRedis_KeysOrderedByAge(t *testing.T) {
+	c, mr := newTestCache(t, time.Minute)
+	mr.Set("other:uid0", "foreign")
+	c.Set("uid1", testOrder("uid1"))
+	mr.FastForward(10 * time.Second)
+	c.Set("uid2", testOrder("uid2"))
+
+	keys := c.Keys()
+
+	require.Len(t, keys, 2)
+	assert.Equal(t, "uid2", keys[0].Key)
+	assert.Equal(t, time.Duration(0), keys[0].Age)
+	assert.Equal(t, "uid1", keys[1].Key)
+	assert.Equal(t, 10*time.Second, keys[1].Age)
+}
+
+func TestRedis_FlushKeepsForeignKeys(t *testing.T) {
+	c, mr := newTestCache(t, time.Minute)
+	mr.Set("other:uid0", "foreign")
+	c.Set("uid1", testOrder("uid1"))
+	c.Set("uid2", testOrder("uid2"))
+
+	c.Flush()
+
+	assert.Empty(t, c.Keys())
+	assert.True(t, mr.Exists("other:uid0"))
+}
+
+func TestRedis_Stats(t *testing.T) {
+	c, _ := newTestCache(t, time.Minute)
+	c.Set("uid1", testOrder("uid1"))
+	c.Get("uid1")
+	c.Get("uid2")
+	c.Peek("uid1")
+
+	assert.Equal(t, cache.Stats{Hits: 1, Misses: 1, Size: 1}, c.Stats())
+}
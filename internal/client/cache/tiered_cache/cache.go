@@ -0,0 +1,124 @@
+package tiered_cache
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/cache"
+)
+
+var _ cache.ReadThroughClient = (*Cache)(nil)
+
+// Cache checks its tiers in order (the fastest one first) and back-fills
+// the faster tiers when a value is found in a slower one.
+type Cache struct {
+	tiers []cache.Client
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func New(tiers ...cache.Client) *Cache {
+	return &Cache{
+		tiers: tiers,
+	}
+}
+
+func (c *Cache) Set(key string, value interface{}) bool {
+	ok := true
+	for _, tier := range c.tiers {
+		ok = tier.Set(key, value) && ok
+	}
+
+	return ok
+}
+
+func (c *Cache) Get(key string) interface{} {
+	for i, tier := range c.tiers {
+		value := tier.Get(key)
+		if value == nil {
+			continue
+		}
+
+		for _, upper := range c.tiers[:i] {
+			upper.Set(key, value)
+		}
+
+		c.hits.Add(1)
+		return value
+	}
+
+	c.misses.Add(1)
+	return nil
+}
+
+func (c *Cache) Remove(key string) bool {
+	ok := true
+	for _, tier := range c.tiers {
+		ok = tier.Remove(key) && ok
+	}
+
+	return ok
+}
+
+func (c *Cache) Peek(key string) interface{} {
+	for _, tier := range c.tiers {
+		if value := tier.Peek(key); value != nil {
+			return value
+		}
+	}
+
+	return nil
+}
+
+// Keys lists the keys of the fastest tier first, followed by keys that are
+// only present in slower tiers.
+func (c *Cache) Keys() []cache.Entry {
+	seen := make(map[string]struct{})
+	var entries []cache.Entry
+	for _, tier := range c.tiers {
+		for _, entry := range tier.Keys() {
+			if _, ok := seen[entry.Key]; ok {
+				continue
+			}
+			seen[entry.Key] = struct{}{}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+func (c *Cache) Flush() {
+	for _, tier := range c.tiers {
+		tier.Flush()
+	}
+}
+
+func (c *Cache) Stats() cache.Stats {
+	stats := cache.Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Size:   len(c.Keys()),
+	}
+	for _, tier := range c.tiers {
+		stats.Evictions += tier.Stats().Evictions
+	}
+
+	return stats
+}
+
+func (c *Cache) GetOrLoad(ctx context.Context, key string, loader cache.Loader) (interface{}, error) {
+	if value := c.Get(key); value != nil {
+		return value, nil
+	}
+
+	value, err := loader(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Set(key, value)
+
+	return value, nil
+}
@@ -0,0 +1,115 @@
+package tiered_cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/lru_cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTiered_GetFromLocal(t *testing.T) {
+	local, remote := lru_cache.New(3), lru_cache.New(3)
+	c := New(local, remote)
+	local.Set("someKey1", 8)
+
+	assert.Equal(t, 8, c.Get("someKey1"))
+	assert.Nil(t, remote.Get("someKey1"))
+}
+
+func TestTiered_GetFromRemoteFillsLocal(t *testing.T) {
+	local, remote := lru_cache.New(3), lru_cache.New(3)
+	c := New(local, remote)
+	remote.Set("someKey1", 8)
+
+	assert.Equal(t, 8, c.Get("someKey1"))
+	assert.Equal(t, 8, local.Get("someKey1"))
+}
+
+func TestTiered_GetHasNotElement(t *testing.T) {
+	c := New(lru_cache.New(3), lru_cache.New(3))
+
+	assert.Nil(t, c.Get("someKey1"))
+}
+
+func TestTiered_SetAndRemoveAllTiers(t *testing.T) {
+	local, remote := lru_cache.New(3), lru_cache.New(3)
+	c := New(local, remote)
+
+	c.Set("someKey1", 8)
+	assert.Equal(t, 8, local.Get("someKey1"))
+	assert.Equal(t, 8, remote.Get("someKey1"))
+
+	c.Remove("someKey1")
+	assert.Nil(t, local.Get("someKey1"))
+	assert.Nil(t, remote.Get("someKey1"))
+}
+
+func TestTiered_GetOrLoadMissFillsAllTiers(t *testing.T) {
+	local, remote := lru_cache.New(3), lru_cache.New(3)
+	c := New(local, remote)
+	calls := 0
+	loader := func(ctx context.Context, key string) (interface{}, error) {
+		calls++
+		return "loaded " + key, nil
+	}
+
+	value, err := c.GetOrLoad(context.Background(), "someKey1", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, "loaded someKey1", value)
+
+	value, err = c.GetOrLoad(context.Background(), "someKey1", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, "loaded someKey1", value)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "loaded someKey1", local.Get("someKey1"))
+	assert.Equal(t, "loaded someKey1", remote.Get("someKey1"))
+}
+
+func TestTiered_GetOrLoadErrorNotCached(t *testing.T) {
+	local := lru_cache.New(3)
+	c := New(local)
+	loadErr := errors.New("db is down")
+
+	value, err := c.GetOrLoad(context.Background(), "someKey1", func(ctx context.Context, key string) (interface{}, error) {
+		return nil, loadErr
+	})
+
+	assert.ErrorIs(t, err, loadErr)
+	assert.Nil(t, value)
+	assert.Nil(t, local.Get("someKey1"))
+}
+
+func TestTiered_KeysMergesTiers(t *testing.T) {
+	local, remote := lru_cache.New(3), lru_cache.New(3)
+	c := New(local, remote)
+	remote.Set("someKey1", 1)
+	c.Set("someKey2", 2)
+
+	keys := c.Keys()
+
+	assert.Len(t, keys, 2)
+	assert.Equal(t, "someKey2", keys[0].Key)
+	assert.Equal(t, "someKey1", keys[1].Key)
+}
+
+func TestTiered_StatsAndFlush(t *testing.T) {
+	local, remote := lru_cache.New(1), lru_cache.New(3)
+	c := New(local, remote)
+	c.Set("someKey1", 1)
+	c.Set("someKey2", 2)
+	c.Get("someKey1")
+	c.Get("someKey3")
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(2), stats.Evictions)
+	assert.Equal(t, 2, stats.Size)
+
+	c.Flush()
+	assert.Nil(t, c.Peek("someKey1"))
+	assert.Nil(t, c.Peek("someKey2"))
+}
@@ -2,12 +2,21 @@ package db
 
 import (
 	"context"
+	"errors"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"io"
 )
 
+// ErrIncompatibleTx is returned when a transaction is requested inside one
+// that is weaker: a stricter isolation level or a write inside a read-only
+// transaction.
+var ErrIncompatibleTx = errors.New("incompatible with the outer transaction")
+
 type Handler func(ctx context.Context) error
 
+type NotificationHandler func(ctx context.Context, payload string) error
+
 type SQLExecer interface {
 	ExecContext(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error)
 	QueryContext(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error)
@@ -31,9 +40,34 @@ type DB interface {
 
 type Client interface {
 	DB() DB
+	// ReplicaDB sends queries outside a transaction to read replicas.
+	ReplicaDB() DB
 	Close() error
 }
 
+// TxManager runs handlers in transactions. A handler called inside an
+// existing transaction joins it instead of starting a new one, so its options
+// must not be stricter than the outer ones: a weaker or equal isolation level
+// runs as is, anything else fails with ErrIncompatibleTx.
 type TxManager interface {
 	ReadCommited(cxt context.Context, f Handler) error
+	RepeatableRead(ctx context.Context, f Handler) error
+	Serializable(ctx context.Context, f Handler) error
+	ReadOnly(ctx context.Context, f Handler) error
+	Nested(ctx context.Context, f Handler) error
+}
+
+// ReconnectHandler is called when LISTEN is re-established after the
+// connection was lost. Notifications sent in between are not delivered.
+type ReconnectHandler func(ctx context.Context)
+
+type Listener interface {
+	Listen(ctx context.Context, channel string, handler NotificationHandler, reconnected ReconnectHandler) error
+}
+
+type Migrator interface {
+	Up(ctx context.Context) error
+	Down(ctx context.Context) error
+	Status(ctx context.Context, w io.Writer) error
+	Close() error
 }
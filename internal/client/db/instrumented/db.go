@@ -0,0 +1,227 @@
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	_ db.Client = (*client)(nil)
+	_ db.DB     = (*instrumentedDB)(nil)
+)
+
+type client struct {
+	db.Client
+	master  db.DB
+	replica db.DB
+}
+
+// NewClient instruments both the primary and the replica DB of the client.
+// Queries slower than slowThreshold are logged; zero disables the log.
+func NewClient(c db.Client, slowThreshold time.Duration) db.Client {
+	return &client{
+		Client:  c,
+		master:  NewDB(c.DB(), slowThreshold),
+		replica: NewDB(c.ReplicaDB(), slowThreshold),
+	}
+}
+
+func (c *client) DB() db.DB {
+	return c.master
+}
+
+func (c *client) ReplicaDB() db.DB {
+	return c.replica
+}
+
+type instrumentedDB struct {
+	db.DB
+	slowThreshold time.Duration
+}
+
+// NewDB records duration, row count and outcome of every query, labelled
+// with the method that issued it, e.g. "order.GetFullOrder".
+func NewDB(d db.DB, slowThreshold time.Duration) db.DB {
+	return &instrumentedDB{
+		DB:            d,
+		slowThreshold: slowThreshold,
+	}
+}
+
+func (d *instrumentedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
+	q := d.start(query, args)
+	tag, err := d.DB.ExecContext(ctx, query, args...)
+	q.finish(tag.RowsAffected(), err)
+
+	return tag, err
+}
+
+func (d *instrumentedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error) {
+	q := d.start(query, args)
+	rows, err := d.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		q.finish(0, err)
+		return nil, err
+	}
+
+	return &trackedRows{Rows: rows, q: q}, nil
+}
+
+func (d *instrumentedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) pgx.Row {
+	q := d.start(query, args)
+	return &trackedRow{Row: d.DB.QueryRowContext(ctx, query, args...), q: q}
+}
+
+func (d *instrumentedDB) start(query string, args []interface{}) *trackedQuery {
+	return &trackedQuery{
+		name:          callerName(),
+		query:         query,
+		args:          args,
+		startedAt:     time.Now(),
+		slowThreshold: d.slowThreshold,
+	}
+}
+
+type trackedQuery struct {
+	name          string
+	query         string
+	args          []interface{}
+	startedAt     time.Time
+	slowThreshold time.Duration
+	once          sync.Once
+}
+
+func (q *trackedQuery) finish(rows int64, err error) {
+	q.once.Do(func() {
+		elapsed := time.Since(q.startedAt)
+
+		status := statusOK
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			status = statusError
+		}
+		queryDuration.WithLabelValues(q.name, status).Observe(elapsed.Seconds())
+		queryRows.WithLabelValues(q.name).Observe(float64(rows))
+
+		if q.slowThreshold > 0 && elapsed >= q.slowThreshold {
+			log.Printf("slow query %s took %s (rows: %d, status: %s): %s args: %s",
+				q.name, elapsed, rows, status, compact(q.query), sanitize(q.args))
+		}
+	})
+}
+
+// trackedRows finishes the measurement when the rows are closed, so the
+// duration includes reading the result.
+type trackedRows struct {
+	pgx.Rows
+	q     *trackedQuery
+	count int64
+}
+
+func (r *trackedRows) Next() bool {
+	if r.Rows.Next() {
+		r.count++
+		return true
+	}
+
+	r.q.finish(r.count, r.Rows.Err())
+	return false
+}
+
+func (r *trackedRows) Close() {
+	r.Rows.Close()
+	r.q.finish(r.count, r.Rows.Err())
+}
+
+type trackedRow struct {
+	pgx.Row
+	q *trackedQuery
+}
+
+func (r *trackedRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+
+	var rows int64
+	if err == nil {
+		rows = 1
+	}
+	r.q.finish(rows, err)
+
+	return err
+}
+
+var callerNames sync.Map
+
+// callerName returns the first function up the stack outside the db client
+// packages, shortened to "package.Method".
+func callerName() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.Function, "/internal/client/db/") {
+			if name, ok := callerNames.Load(frame.PC); ok {
+				return name.(string)
+			}
+			name := shortName(frame.Function)
+			callerNames.Store(frame.PC, name)
+			return name
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+func shortName(function string) string {
+	function = function[strings.LastIndex(function, "/")+1:]
+	function = strings.NewReplacer("(*", "", ")", "").Replace(function)
+
+	parts := strings.Split(function, ".")
+	if len(parts) > 2 {
+		// package.type.Method -> package.Method, dropping closure suffixes.
+		return parts[0] + "." + parts[2]
+	}
+
+	return function
+}
+
+func compact(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// sanitize describes the arguments without their values, which may contain
+// personal data, keeping only types, lengths and numbers.
+func sanitize(args []interface{}) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+			parts = append(parts, "NULL")
+		case string:
+			parts = append(parts, fmt.Sprintf("<string len=%d>", len(v)))
+		case []byte:
+			parts = append(parts, fmt.Sprintf("<bytes len=%d>", len(v)))
+		case []string:
+			parts = append(parts, fmt.Sprintf("<%d strings>", len(v)))
+		case int, int32, int64, uint64, float64, bool:
+			parts = append(parts, fmt.Sprint(v))
+		case time.Time:
+			parts = append(parts, v.Format(time.RFC3339))
+		default:
+			parts = append(parts, fmt.Sprintf("<%T>", v))
+		}
+	}
+
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,95 @@
+package instrumented
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRows struct {
+	pgx.Rows
+	left   int
+	closed bool
+}
+
+func (r *fakeRows) Next() bool {
+	r.left--
+	return r.left >= 0
+}
+
+func (r *fakeRows) Err() error {
+	return nil
+}
+
+func (r *fakeRows) Close() {
+	r.closed = true
+}
+
+type fakeRow struct{}
+
+func (fakeRow) Scan(dest ...any) error {
+	return pgx.ErrNoRows
+}
+
+type fakeDB struct {
+	db.DB
+	rows     *fakeRows
+	rowDelay time.Duration
+}
+
+func (d *fakeDB) QueryContext(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error) {
+	return d.rows, nil
+}
+
+func (d *fakeDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) pgx.Row {
+	time.Sleep(d.rowDelay)
+	return fakeRow{}
+}
+
+func TestDB_CountsRows(t *testing.T) {
+	inner := &fakeDB{rows: &fakeRows{left: 3}}
+	d := NewDB(inner, time.Nanosecond)
+
+	rows, err := d.QueryContext(context.Background(), "SELECT 1", "secret")
+	require.NoError(t, err)
+	for rows.Next() {
+	}
+	rows.Close()
+
+	tracked := rows.(*trackedRows)
+	assert.EqualValues(t, 3, tracked.count)
+	assert.True(t, inner.rows.closed)
+
+	err = d.QueryRowContext(context.Background(), "SELECT 1").Scan()
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	assert.Positive(t, testutil.CollectAndCount(queryDuration))
+}
+
+func TestDB_QueryRowTimesTheQuery(t *testing.T) {
+	inner := &fakeDB{rowDelay: 10 * time.Millisecond}
+	d := NewDB(inner, 0)
+
+	row := d.QueryRowContext(context.Background(), "SELECT 1").(*trackedRow)
+	assert.GreaterOrEqual(t, time.Since(row.q.startedAt), inner.rowDelay)
+}
+
+func TestShortName(t *testing.T) {
+	assert.Equal(t, "order.GetFullOrder", shortName("github.com/biryanim/wb_tech_L0/internal/repository/order.(*repo).GetFullOrder"))
+	assert.Equal(t, "order.RecordReads", shortName("github.com/biryanim/wb_tech_L0/internal/repository/order.(*repo).RecordReads.func1"))
+	assert.Equal(t, "main.restore", shortName("main.restore"))
+}
+
+func TestSanitize(t *testing.T) {
+	at := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	got := sanitize([]interface{}{"Ivan Petrov", 42, []string{"a", "b"}, nil, at, []byte("{}")})
+
+	assert.Equal(t, "[<string len=11>, 42, <2 strings>, NULL, 2025-09-01T12:00:00Z, <bytes len=2>]", got)
+	assert.NotContains(t, got, "Ivan")
+}
@@ -0,0 +1,27 @@
+package instrumented
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	statusOK    = "ok"
+	statusError = "error"
+)
+
+var (
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of database queries by calling method.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"query", "status"})
+
+	queryRows = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "db",
+		Name:      "query_rows",
+		Help:      "Rows returned or affected by database queries by calling method.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{"query"})
+)
@@ -0,0 +1,101 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/pkg/errors"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+var _ db.Migrator = (*migrator)(nil)
+
+type migrator struct {
+	db       *sql.DB
+	provider *goose.Provider
+}
+
+// New applies the migrations from fsys to the database at dsn. Every command
+// holds a Postgres advisory lock, so replicas starting together apply the
+// migrations once.
+func New(dsn string, fsys fs.FS) (*migrator, error) {
+	sqlDB, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open db")
+	}
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		sqlDB.Close()
+		return nil, errors.Wrap(err, "failed to create migration lock")
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, sqlDB, fsys, goose.WithSessionLocker(locker))
+	if err != nil {
+		sqlDB.Close()
+		return nil, errors.Wrap(err, "failed to create migration provider")
+	}
+
+	return &migrator{
+		db:       sqlDB,
+		provider: provider,
+	}, nil
+}
+
+func (m *migrator) Up(ctx context.Context) error {
+	results, err := m.provider.Up(ctx)
+	for _, result := range results {
+		log.Printf("migration %s", result)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to apply migrations")
+	}
+	if len(results) == 0 {
+		log.Println("migrations are up to date")
+	}
+
+	return nil
+}
+
+func (m *migrator) Down(ctx context.Context) error {
+	result, err := m.provider.Down(ctx)
+	if result != nil {
+		log.Printf("migration %s", result)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to roll back migration")
+	}
+
+	return nil
+}
+
+func (m *migrator) Status(ctx context.Context, w io.Writer) error {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get migration status")
+	}
+
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.State == goose.StateApplied {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		_, err = fmt.Fprintf(w, "%-20s %s\n", appliedAt, status.Source.Path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *migrator) Close() error {
+	return m.db.Close()
+}
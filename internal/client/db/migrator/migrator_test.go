@@ -0,0 +1,27 @@
+package migrator
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/biryanim/wb_tech_L0/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_FindsEmbeddedMigrations(t *testing.T) {
+	// sql.Open does not connect, so the sources are listed without a database.
+	m, err := New("host=localhost dbname=orders", migrations.FS)
+	require.NoError(t, err)
+	defer m.Close()
+
+	files, err := fs.Glob(migrations.FS, "*.sql")
+	require.NoError(t, err)
+
+	sources := m.provider.ListSources()
+	require.Len(t, sources, len(files))
+	assert.NotEmpty(t, files)
+	for i, source := range sources {
+		assert.Equal(t, files[i], source.Path)
+	}
+}
@@ -8,24 +8,59 @@ import (
 )
 
 type pgClient struct {
-	masterDBC db.DB
+	masterDBC  db.DB
+	replicaDBC *replicaSet
 }
 
-func New(ctx context.Context, dsn string) (db.Client, error) {
+// New connects to the primary and to the optional read replicas. Without
+// replicas, ReplicaDB returns the primary.
+func New(ctx context.Context, dsn string, replicaDSNs ...string) (db.Client, error) {
 	dbc, err := pgxpool.New(ctx, dsn)
 	if err != nil {
 		return nil, errors.Errorf("failed to connect to database: %v", err)
 	}
 
-	return &pgClient{
+	client := &pgClient{
 		masterDBC: NewDB(dbc),
-	}, nil
+	}
+
+	if len(replicaDSNs) == 0 {
+		return client, nil
+	}
+
+	replicas := make([]db.DB, 0, len(replicaDSNs))
+	for _, replicaDSN := range replicaDSNs {
+		replicaDBC, err := pgxpool.New(ctx, replicaDSN)
+		if err != nil {
+			for _, r := range replicas {
+				r.Close()
+			}
+			client.masterDBC.Close()
+			return nil, errors.Errorf("failed to connect to replica: %v", err)
+		}
+		replicas = append(replicas, NewDB(replicaDBC))
+	}
+	client.replicaDBC = newReplicaSet(client.masterDBC, replicas)
+
+	return client, nil
 }
+
 func (c *pgClient) DB() db.DB {
 	return c.masterDBC
 }
 
+func (c *pgClient) ReplicaDB() db.DB {
+	if c.replicaDBC == nil {
+		return c.masterDBC
+	}
+
+	return c.replicaDBC
+}
+
 func (c *pgClient) Close() error {
+	if c.replicaDBC != nil {
+		c.replicaDBC.Close()
+	}
 	if c.masterDBC != nil {
 		c.masterDBC.Close()
 	}
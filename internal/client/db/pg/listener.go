@@ -0,0 +1,88 @@
+package pg
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+const (
+	minReconnectDelay = 500 * time.Millisecond
+	maxReconnectDelay = 30 * time.Second
+)
+
+var _ db.Listener = (*listener)(nil)
+
+// listener keeps a dedicated connection outside the pool, since LISTEN is
+// bound to the session that issued it.
+type listener struct {
+	dsn string
+}
+
+func NewListener(dsn string) db.Listener {
+	return &listener{
+		dsn: dsn,
+	}
+}
+
+// Listen delivers notifications to handler until ctx is done, reconnecting
+// with exponential backoff when the connection is lost. reconnected is called
+// every time listening resumes after a reconnect.
+func (l *listener) Listen(ctx context.Context, channel string, handler db.NotificationHandler, reconnected db.ReconnectHandler) error {
+	delay := minReconnectDelay
+	listened := false
+	for {
+		err := l.listen(ctx, channel, handler, func() {
+			delay = minReconnectDelay
+			if listened {
+				reconnected(ctx)
+			}
+			listened = true
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		log.Printf("listener on %s failed, reconnecting in %s: %v", channel, delay, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+func (l *listener) listen(ctx context.Context, channel string, handler db.NotificationHandler, connected func()) error {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize())
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %s", channel)
+	}
+	connected()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to wait for notification")
+		}
+
+		err = handler(ctx, notification.Payload)
+		if err != nil {
+			log.Printf("error handling notification on %s: %v", channel, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package pg
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	healthCheckInterval = 5 * time.Second
+	healthCheckTimeout  = time.Second
+)
+
+var _ db.DB = (*replicaSet)(nil)
+
+type replica struct {
+	db      db.DB
+	healthy atomic.Bool
+}
+
+// replicaSet sends queries to healthy replicas in round-robin order and
+// falls back to the primary when none is healthy. Writes and transactions
+// always go to the primary; queries inside a transaction follow it there,
+// since pg uses the transaction from the context.
+type replicaSet struct {
+	primary  db.DB
+	replicas []*replica
+	next     atomic.Uint64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newReplicaSet(primary db.DB, replicas []db.DB) *replicaSet {
+	rs := &replicaSet{
+		primary: primary,
+		stop:    make(chan struct{}),
+	}
+	for _, r := range replicas {
+		rep := &replica{db: r}
+		rep.healthy.Store(true)
+		rs.replicas = append(rs.replicas, rep)
+	}
+
+	rs.wg.Add(1)
+	go rs.healthCheck()
+
+	return rs
+}
+
+func (rs *replicaSet) ExecContext(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
+	return rs.primary.ExecContext(ctx, query, args...)
+}
+
+func (rs *replicaSet) QueryContext(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error) {
+	return rs.reader(ctx).QueryContext(ctx, query, args...)
+}
+
+func (rs *replicaSet) QueryRowContext(ctx context.Context, query string, args ...interface{}) pgx.Row {
+	return rs.reader(ctx).QueryRowContext(ctx, query, args...)
+}
+
+func (rs *replicaSet) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return rs.primary.BeginTx(ctx, txOptions)
+}
+
+func (rs *replicaSet) Ping(ctx context.Context) error {
+	return rs.primary.Ping(ctx)
+}
+
+// Close stops the health checks and closes the replicas. The primary is
+// owned by the client and closed there.
+func (rs *replicaSet) Close() {
+	close(rs.stop)
+	rs.wg.Wait()
+
+	for _, r := range rs.replicas {
+		r.db.Close()
+	}
+}
+
+func (rs *replicaSet) reader(ctx context.Context) db.DB {
+	if _, ok := ctx.Value(TxKey).(pgx.Tx); ok {
+		return rs.primary
+	}
+
+	for range rs.replicas {
+		r := rs.replicas[(rs.next.Add(1)-1)%uint64(len(rs.replicas))]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+
+	return rs.primary
+}
+
+func (rs *replicaSet) healthCheck() {
+	defer rs.wg.Done()
+
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rs.stop:
+			return
+		case <-ticker.C:
+			rs.checkReplicas()
+		}
+	}
+}
+
+func (rs *replicaSet) checkReplicas() {
+	for i, r := range rs.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		err := r.db.Ping(ctx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) != healthy {
+			if healthy {
+				log.Printf("pg replica %d is back", i)
+			} else {
+				log.Printf("pg replica %d is unavailable: %v", i, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package pg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDB struct {
+	db.DB
+	name    string
+	pingErr error
+	closed  bool
+}
+
+func (f *fakeDB) Ping(ctx context.Context) error {
+	return f.pingErr
+}
+
+func (f *fakeDB) Close() {
+	f.closed = true
+}
+
+func newTestReplicaSet(replicas ...*fakeDB) (*replicaSet, *fakeDB) {
+	primary := &fakeDB{name: "primary"}
+	dbs := make([]db.DB, 0, len(replicas))
+	for _, r := range replicas {
+		dbs = append(dbs, r)
+	}
+
+	return newReplicaSet(primary, dbs), primary
+}
+
+func TestReplicaSet_RoundRobin(t *testing.T) {
+	r1, r2 := &fakeDB{name: "r1"}, &fakeDB{name: "r2"}
+	rs, _ := newTestReplicaSet(r1, r2)
+	defer rs.Close()
+
+	ctx := context.Background()
+	assert.Same(t, r1, rs.reader(ctx))
+	assert.Same(t, r2, rs.reader(ctx))
+	assert.Same(t, r1, rs.reader(ctx))
+}
+
+func TestReplicaSet_SkipsUnhealthyReplicas(t *testing.T) {
+	r1, r2 := &fakeDB{name: "r1", pingErr: errors.New("down")}, &fakeDB{name: "r2"}
+	rs, primary := newTestReplicaSet(r1, r2)
+	defer rs.Close()
+
+	rs.checkReplicas()
+	ctx := context.Background()
+	assert.Same(t, r2, rs.reader(ctx))
+	assert.Same(t, r2, rs.reader(ctx))
+
+	r2.pingErr = errors.New("down")
+	rs.checkReplicas()
+	assert.Same(t, primary, rs.reader(ctx))
+
+	r1.pingErr = nil
+	rs.checkReplicas()
+	assert.Same(t, r1, rs.reader(ctx))
+}
+
+func TestReplicaSet_TransactionsStayOnPrimary(t *testing.T) {
+	rs, primary := newTestReplicaSet(&fakeDB{name: "r1"})
+	defer rs.Close()
+
+	ctx := MakeContextTx(context.Background(), fakeTx{})
+	assert.Same(t, primary, rs.reader(ctx))
+}
+
+func TestReplicaSet_CloseLeavesPrimaryOpen(t *testing.T) {
+	r1 := &fakeDB{name: "r1"}
+	rs, primary := newTestReplicaSet(r1)
+	rs.Close()
+
+	assert.True(t, r1.closed)
+	assert.False(t, primary.closed)
+}
+
+type fakeTx struct {
+	pgx.Tx
+}
@@ -2,19 +2,49 @@ package transaction
 
 import (
 	"context"
+	"math/rand/v2"
+	"time"
+
 	"github.com/biryanim/wb_tech_L0/internal/client/db"
 	"github.com/biryanim/wb_tech_L0/internal/client/db/pg"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pkg/errors"
 )
 
+const (
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+
+	defaultMaxRetries = 3
+	defaultRetryDelay = 20 * time.Millisecond
+	maxRetryDelay     = time.Second
+)
+
+type txOptionsKeyType struct{}
+
+var txOptionsKey = txOptionsKeyType{}
+
+// isolationStrength orders isolation levels from the weakest to the
+// strictest.
+var isolationStrength = map[pgx.TxIsoLevel]int{
+	pgx.ReadUncommitted: 0,
+	pgx.ReadCommitted:   1,
+	pgx.RepeatableRead:  2,
+	pgx.Serializable:    3,
+}
+
 type manager struct {
-	db db.Transactor
+	db         db.Transactor
+	maxRetries int
+	retryDelay time.Duration
 }
 
 func NewTransactionManager(db db.Transactor) db.TxManager {
 	return &manager{
-		db: db,
+		db:         db,
+		maxRetries: defaultMaxRetries,
+		retryDelay: defaultRetryDelay,
 	}
 }
 
@@ -25,19 +55,104 @@ func (m *manager) ReadCommited(cxt context.Context, f db.Handler) error {
 	return m.transaction(cxt, txOpts, f)
 }
 
-func (m *manager) transaction(ctx context.Context, opts pgx.TxOptions, fn db.Handler) (err error) {
+func (m *manager) RepeatableRead(ctx context.Context, f db.Handler) error {
+	txOpts := pgx.TxOptions{
+		IsoLevel: pgx.RepeatableRead,
+	}
+	return m.transaction(ctx, txOpts, f)
+}
+
+func (m *manager) Serializable(ctx context.Context, f db.Handler) error {
+	txOpts := pgx.TxOptions{
+		IsoLevel: pgx.Serializable,
+	}
+	return m.transaction(ctx, txOpts, f)
+}
+
+// ReadOnly runs f on one consistent snapshot of the database.
+func (m *manager) ReadOnly(ctx context.Context, f db.Handler) error {
+	txOpts := pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	}
+	return m.transaction(ctx, txOpts, f)
+}
+
+// Nested runs f under a savepoint when ctx already holds a transaction, so a
+// failure of f is rolled back without aborting the outer transaction. The
+// error is still returned and the caller decides whether to go on.
+// Without an outer transaction it behaves like ReadCommited.
+func (m *manager) Nested(ctx context.Context, f db.Handler) (err error) {
 	tx, ok := ctx.Value(pg.TxKey).(pgx.Tx)
+	if !ok {
+		return m.ReadCommited(ctx, f)
+	}
+
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "cannot create savepoint")
+	}
+
+	return m.run(pg.MakeContextTx(ctx, savepoint), savepoint, f)
+}
+
+// transaction reuses the transaction from ctx if there is one and its
+// options are at least as strict as opts. Otherwise it starts a new one and
+// retries it on serialization failures and deadlocks, so f must be safe to
+// run more than once.
+func (m *manager) transaction(ctx context.Context, opts pgx.TxOptions, fn db.Handler) (err error) {
+	_, ok := ctx.Value(pg.TxKey).(pgx.Tx)
 	if ok {
+		outer, _ := ctx.Value(txOptionsKey).(pgx.TxOptions)
+		if err = compatible(outer, opts); err != nil {
+			return err
+		}
 		return fn(ctx)
 	}
 
-	tx, err = m.db.BeginTx(ctx, opts)
+	for attempt := 0; ; attempt++ {
+		err = m.begin(ctx, opts, fn)
+		if err == nil || attempt >= m.maxRetries || !retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(m.backoff(attempt)):
+		}
+	}
+}
+
+func (m *manager) begin(ctx context.Context, opts pgx.TxOptions, fn db.Handler) error {
+	tx, err := m.db.BeginTx(ctx, opts)
 	if err != nil {
 		return errors.Wrap(err, "cannot begin transaction")
 	}
 
-	ctx = pg.MakeContextTx(ctx, tx)
+	ctx = context.WithValue(ctx, txOptionsKey, opts)
+	return m.run(pg.MakeContextTx(ctx, tx), tx, fn)
+}
 
+// compatible reports whether a transaction with inner options can join one
+// with outer options. A transaction started outside the manager has no known
+// options and accepts anything.
+func compatible(outer, inner pgx.TxOptions) error {
+	if outer == (pgx.TxOptions{}) {
+		return nil
+	}
+
+	if isolationStrength[inner.IsoLevel] > isolationStrength[outer.IsoLevel] {
+		return errors.Wrapf(db.ErrIncompatibleTx, "cannot run %s inside %s", inner.IsoLevel, outer.IsoLevel)
+	}
+	if inner.AccessMode != pgx.ReadOnly && outer.AccessMode == pgx.ReadOnly {
+		return errors.Wrap(db.ErrIncompatibleTx, "cannot write inside a read-only transaction")
+	}
+
+	return nil
+}
+
+func (m *manager) run(ctx context.Context, tx pgx.Tx, fn db.Handler) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = errors.Errorf("panic recovered: %v", r)
@@ -65,3 +180,17 @@ func (m *manager) transaction(ctx context.Context, opts pgx.TxOptions, fn db.Han
 
 	return err
 }
+
+func (m *manager) backoff(attempt int) time.Duration {
+	delay := min(m.retryDelay<<attempt, maxRetryDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == serializationFailureCode || pgErr.Code == deadlockDetectedCode
+}
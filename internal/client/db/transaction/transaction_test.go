@@ -0,0 +1,161 @@
+package transaction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	"github.com/biryanim/wb_tech_L0/internal/client/db/pg"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTx struct {
+	pgx.Tx
+	committed  bool
+	rolledBack bool
+	savepoints []*fakeTx
+}
+
+func (tx *fakeTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	savepoint := &fakeTx{}
+	tx.savepoints = append(tx.savepoints, savepoint)
+	return savepoint, nil
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	tx.rolledBack = true
+	return nil
+}
+
+type fakeTransactor struct {
+	txs  []*fakeTx
+	opts []pgx.TxOptions
+}
+
+func (t *fakeTransactor) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	tx := &fakeTx{}
+	t.txs = append(t.txs, tx)
+	t.opts = append(t.opts, txOptions)
+	return tx, nil
+}
+
+func newTestManager(t *fakeTransactor) *manager {
+	m := NewTransactionManager(t).(*manager)
+	m.retryDelay = 0
+	return m
+}
+
+func TestManager_RetriesSerializationFailures(t *testing.T) {
+	transactor := &fakeTransactor{}
+	m := newTestManager(transactor)
+
+	calls := 0
+	err := m.Serializable(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return &pgconn.PgError{Code: serializationFailureCode}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, calls)
+	require.Len(t, transactor.txs, 3)
+	assert.True(t, transactor.txs[0].rolledBack)
+	assert.True(t, transactor.txs[2].committed)
+	assert.Equal(t, pgx.Serializable, transactor.opts[0].IsoLevel)
+}
+
+func TestManager_GivesUpAfterMaxRetries(t *testing.T) {
+	transactor := &fakeTransactor{}
+	m := newTestManager(transactor)
+
+	err := m.RepeatableRead(context.Background(), func(ctx context.Context) error {
+		return &pgconn.PgError{Code: deadlockDetectedCode}
+	})
+
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(err, &pgErr))
+	assert.Len(t, transactor.txs, defaultMaxRetries+1)
+}
+
+func TestManager_DoesNotRetryOtherErrors(t *testing.T) {
+	transactor := &fakeTransactor{}
+	m := newTestManager(transactor)
+
+	err := m.ReadCommited(context.Background(), func(ctx context.Context) error {
+		return &pgconn.PgError{Code: "23505"}
+	})
+
+	assert.Error(t, err)
+	assert.Len(t, transactor.txs, 1)
+}
+
+func TestManager_ReadOnly(t *testing.T) {
+	transactor := &fakeTransactor{}
+	m := newTestManager(transactor)
+
+	require.NoError(t, m.ReadOnly(context.Background(), func(ctx context.Context) error { return nil }))
+	assert.Equal(t, pgx.ReadOnly, transactor.opts[0].AccessMode)
+}
+
+func TestManager_NestedIsolatesInnerFailure(t *testing.T) {
+	transactor := &fakeTransactor{}
+	m := newTestManager(transactor)
+
+	innerErr := errors.New("inner failed")
+	err := m.ReadCommited(context.Background(), func(ctx context.Context) error {
+		err := m.Nested(ctx, func(ctx context.Context) error {
+			return innerErr
+		})
+		assert.ErrorIs(t, err, innerErr)
+
+		return m.Nested(ctx, func(ctx context.Context) error {
+			_, ok := ctx.Value(pg.TxKey).(pgx.Tx)
+			assert.True(t, ok)
+			return nil
+		})
+	})
+	require.NoError(t, err)
+
+	outer := transactor.txs[0]
+	assert.True(t, outer.committed)
+	require.Len(t, outer.savepoints, 2)
+	assert.True(t, outer.savepoints[0].rolledBack)
+	assert.True(t, outer.savepoints[1].committed)
+}
+
+func TestManager_JoinsOuterTransactionWithWeakerOptions(t *testing.T) {
+	transactor := &fakeTransactor{}
+	m := newTestManager(transactor)
+
+	err := m.Serializable(context.Background(), func(ctx context.Context) error {
+		return m.ReadCommited(ctx, func(ctx context.Context) error { return nil })
+	})
+	require.NoError(t, err)
+	assert.Len(t, transactor.txs, 1)
+}
+
+func TestManager_RejectsStricterNestedOptions(t *testing.T) {
+	transactor := &fakeTransactor{}
+	m := newTestManager(transactor)
+
+	err := m.ReadCommited(context.Background(), func(ctx context.Context) error {
+		return m.Serializable(ctx, func(ctx context.Context) error { return nil })
+	})
+	assert.ErrorIs(t, err, db.ErrIncompatibleTx)
+
+	err = m.ReadOnly(context.Background(), func(ctx context.Context) error {
+		return m.RepeatableRead(ctx, func(ctx context.Context) error { return nil })
+	})
+	assert.ErrorIs(t, err, db.ErrIncompatibleTx)
+}
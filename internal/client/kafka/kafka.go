@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"fmt"
 	"github.com/IBM/sarama"
 )
 
@@ -11,3 +12,8 @@ type Consumer interface {
 	Consume(ctx context.Context, topicName string, handler Handler) (err error)
 	Close() error
 }
+
+// Source identifies a message for audit records as topic/partition@offset.
+func Source(msg *sarama.ConsumerMessage) string {
+	return fmt.Sprintf("kafka:%s/%d@%d", msg.Topic, msg.Partition, msg.Offset)
+}
@@ -1,16 +1,40 @@
 package config
 
 import (
+	"time"
+
 	"github.com/IBM/sarama"
 	"github.com/joho/godotenv"
 )
 
+const (
+	CacheTypeLRU    = "lru"
+	CacheTypeRedis  = "redis"
+	CacheTypeTiered = "tiered"
+)
+
+const (
+	WarmUpStrategyRecent    = "recent"
+	WarmUpStrategyMostRead  = "most_read"
+	WarmUpStrategyCustomers = "customers"
+	WarmUpStrategyNone      = "none"
+)
+
 type PGConfig interface {
 	DSN() string
+	ReplicaDSNs() []string
+	SlowQueryThreshold() time.Duration
+}
+
+type MigrationConfig interface {
+	DSN() string
 }
 
 type HTTPConfig interface {
 	Address() string
+	// AdminToken is the bearer token of the admin endpoints. They are
+	// disabled when it is empty.
+	AdminToken() string
 }
 
 type KafkaConsumerConfig interface {
@@ -19,6 +43,43 @@ type KafkaConsumerConfig interface {
 	Config() *sarama.Config
 }
 
+type CacheConfig interface {
+	Type() string
+	Capacity() int
+	SoftTTL() time.Duration
+	HardTTL() time.Duration
+	NegativeTTL() time.Duration
+	SnapshotPath() string
+	SnapshotMaxAge() time.Duration
+}
+
+type RedisConfig interface {
+	Addr() string
+	Password() string
+	DB() int
+	TTL() time.Duration
+	KeyPrefix() string
+}
+
+type WarmUpConfig interface {
+	Strategy() string
+	Limit() int
+	CustomerIDs() []string
+	ChunkSize() int
+	AccessLogFlushInterval() time.Duration
+}
+
+type ExportConfig interface {
+	ChunkSize() int
+}
+
+type RetentionConfig interface {
+	MaxAge() time.Duration
+	ArchiveDir() string
+	Interval() time.Duration
+	PartitionsAhead() int
+}
+
 func Load(path string) error {
 	err := godotenv.Load(path)
 	if err != nil {
@@ -0,0 +1,140 @@
+package env
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	cacheTypeEnvName           = "CACHE_TYPE"
+	cacheCapacityEnvName       = "CACHE_CAPACITY"
+	cacheSoftTTLEnvName        = "CACHE_SOFT_TTL"
+	cacheHardTTLEnvName        = "CACHE_HARD_TTL"
+	cacheNegativeTTLEnvName    = "CACHE_NEGATIVE_TTL"
+	cacheSnapshotPathEnvName   = "CACHE_SNAPSHOT_PATH"
+	cacheSnapshotMaxAgeEnvName = "CACHE_SNAPSHOT_MAX_AGE"
+
+	defaultCacheCapacity       = 1000
+	defaultCacheSoftTTL        = 5 * time.Minute
+	defaultCacheHardTTL        = time.Hour
+	defaultCacheNegativeTTL    = 30 * time.Second
+	defaultCacheSnapshotMaxAge = 10 * time.Minute
+)
+
+type cacheConfig struct {
+	cacheType      string
+	capacity       int
+	softTTL        time.Duration
+	hardTTL        time.Duration
+	negativeTTL    time.Duration
+	snapshotPath   string
+	snapshotMaxAge time.Duration
+}
+
+func NewCacheConfig() (config.CacheConfig, error) {
+	cacheType := os.Getenv(cacheTypeEnvName)
+	if len(cacheType) == 0 {
+		cacheType = config.CacheTypeLRU
+	}
+
+	switch cacheType {
+	case config.CacheTypeLRU, config.CacheTypeRedis, config.CacheTypeTiered:
+	default:
+		return nil, errors.Errorf("unknown cache type: %s", cacheType)
+	}
+
+	capacity := defaultCacheCapacity
+	if capacityStr := os.Getenv(cacheCapacityEnvName); len(capacityStr) != 0 {
+		var err error
+		capacity, err = strconv.Atoi(capacityStr)
+		if err != nil || capacity <= 0 {
+			return nil, errors.Errorf("invalid cache capacity: %s", capacityStr)
+		}
+	}
+
+	softTTL := defaultCacheSoftTTL
+	if ttlStr := os.Getenv(cacheSoftTTLEnvName); len(ttlStr) != 0 {
+		var err error
+		softTTL, err = time.ParseDuration(ttlStr)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cache soft ttl")
+		}
+	}
+
+	hardTTL := defaultCacheHardTTL
+	if ttlStr := os.Getenv(cacheHardTTLEnvName); len(ttlStr) != 0 {
+		var err error
+		hardTTL, err = time.ParseDuration(ttlStr)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cache hard ttl")
+		}
+	}
+	// Stale entries are served while they are refreshed, so without a hard
+	// ttl a failing refresh would serve them forever.
+	if softTTL > 0 && hardTTL == 0 {
+		return nil, errors.New("cache hard ttl is required with a soft ttl")
+	}
+	if softTTL > 0 && hardTTL > 0 && softTTL > hardTTL {
+		return nil, errors.New("cache soft ttl must not exceed hard ttl")
+	}
+
+	negativeTTL := defaultCacheNegativeTTL
+	if ttlStr := os.Getenv(cacheNegativeTTLEnvName); len(ttlStr) != 0 {
+		var err error
+		negativeTTL, err = time.ParseDuration(ttlStr)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cache negative ttl")
+		}
+	}
+
+	snapshotMaxAge := defaultCacheSnapshotMaxAge
+	if maxAgeStr := os.Getenv(cacheSnapshotMaxAgeEnvName); len(maxAgeStr) != 0 {
+		var err error
+		snapshotMaxAge, err = time.ParseDuration(maxAgeStr)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cache snapshot max age")
+		}
+	}
+
+	return &cacheConfig{
+		cacheType:      cacheType,
+		capacity:       capacity,
+		softTTL:        softTTL,
+		hardTTL:        hardTTL,
+		negativeTTL:    negativeTTL,
+		snapshotPath:   os.Getenv(cacheSnapshotPathEnvName),
+		snapshotMaxAge: snapshotMaxAge,
+	}, nil
+}
+
+func (cfg *cacheConfig) Type() string {
+	return cfg.cacheType
+}
+
+func (cfg *cacheConfig) Capacity() int {
+	return cfg.capacity
+}
+
+func (cfg *cacheConfig) SoftTTL() time.Duration {
+	return cfg.softTTL
+}
+
+func (cfg *cacheConfig) HardTTL() time.Duration {
+	return cfg.hardTTL
+}
+
+func (cfg *cacheConfig) NegativeTTL() time.Duration {
+	return cfg.negativeTTL
+}
+
+func (cfg *cacheConfig) SnapshotPath() string {
+	return cfg.snapshotPath
+}
+
+func (cfg *cacheConfig) SnapshotMaxAge() time.Duration {
+	return cfg.snapshotMaxAge
+}
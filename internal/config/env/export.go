@@ -0,0 +1,46 @@
+package env
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/biryanim/wb_tech_L0/internal/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	exportChunkSizeEnvName = "EXPORT_CHUNK_SIZE"
+
+	defaultExportChunkSize = 1000
+)
+
+type exportConfig struct {
+	chunkSize int
+}
+
+func NewExportConfig() (config.ExportConfig, error) {
+	chunkSize, err := chunkSizeFromEnv(exportChunkSizeEnvName, defaultExportChunkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &exportConfig{chunkSize: chunkSize}, nil
+}
+
+func (cfg *exportConfig) ChunkSize() int {
+	return cfg.chunkSize
+}
+
+func chunkSizeFromEnv(envName string, defaultSize int) (int, error) {
+	chunkSizeStr := os.Getenv(envName)
+	if len(chunkSizeStr) == 0 {
+		return defaultSize, nil
+	}
+
+	chunkSize, err := strconv.Atoi(chunkSizeStr)
+	if err != nil || chunkSize <= 0 {
+		return 0, errors.Errorf("invalid chunk size %s: %s", envName, chunkSizeStr)
+	}
+
+	return chunkSize, nil
+}
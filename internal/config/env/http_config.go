@@ -10,13 +10,15 @@ import (
 )
 
 const (
-	httpHostEnvName = "HTTP_HOST"
-	httpPortEnvName = "HTTP_PORT"
+	httpHostEnvName   = "HTTP_HOST"
+	httpPortEnvName   = "HTTP_PORT"
+	adminTokenEnvName = "ADMIN_TOKEN"
 )
 
 type httpConfig struct {
-	host string
-	port string
+	host       string
+	port       string
+	adminToken string
 }
 
 func NewHTTPConfig() (config.HTTPConfig, error) {
@@ -31,11 +33,16 @@ func NewHTTPConfig() (config.HTTPConfig, error) {
 	}
 
 	return &httpConfig{
-		host: host,
-		port: port,
+		host:       host,
+		port:       port,
+		adminToken: os.Getenv(adminTokenEnvName),
 	}, nil
 }
 
 func (c *httpConfig) Address() string {
 	return net.JoinHostPort(c.host, c.port)
 }
+
+func (c *httpConfig) AdminToken() string {
+	return c.adminToken
+}
@@ -0,0 +1,34 @@
+package env
+
+import (
+	"os"
+
+	"github.com/biryanim/wb_tech_L0/internal/config"
+	"github.com/pkg/errors"
+)
+
+const migrationDSNEnvName = "MIGRATION_DSN"
+
+type migrationConfig struct {
+	dsn string
+}
+
+// NewMigrationConfig falls back to PG_DSN when migrations don't need a
+// separate database user.
+func NewMigrationConfig() (config.MigrationConfig, error) {
+	dsn := os.Getenv(migrationDSNEnvName)
+	if len(dsn) == 0 {
+		dsn = os.Getenv(dsnEnvName)
+	}
+	if len(dsn) == 0 {
+		return nil, errors.New("migration dsn not found")
+	}
+
+	return &migrationConfig{
+		dsn: dsn,
+	}, nil
+}
+
+func (cfg *migrationConfig) DSN() string {
+	return cfg.dsn
+}
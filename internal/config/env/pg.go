@@ -3,14 +3,22 @@ package env
 import (
 	"github.com/pkg/errors"
 	"os"
+	"strings"
+	"time"
 )
 
 const (
-	dsnEnvName = "PG_DSN"
+	dsnEnvName         = "PG_DSN"
+	replicaDSNsEnvName = "PG_REPLICA_DSNS"
+	slowQueryEnvName   = "PG_SLOW_QUERY_THRESHOLD"
+
+	defaultSlowQueryThreshold = 200 * time.Millisecond
 )
 
 type pgConfig struct {
-	dsn string
+	dsn                string
+	replicaDSNs        []string
+	slowQueryThreshold time.Duration
 }
 
 func NewPGConfig() (*pgConfig, error) {
@@ -19,9 +27,39 @@ func NewPGConfig() (*pgConfig, error) {
 		return nil, errors.New("pg dsn not found")
 	}
 
-	return &pgConfig{dsn: dsn}, nil
+	// DSNs in the key=value form contain spaces, so replicas are separated
+	// by semicolons.
+	var replicaDSNs []string
+	for _, replicaDSN := range strings.Split(os.Getenv(replicaDSNsEnvName), ";") {
+		if replicaDSN = strings.TrimSpace(replicaDSN); len(replicaDSN) != 0 {
+			replicaDSNs = append(replicaDSNs, replicaDSN)
+		}
+	}
+
+	slowQueryThreshold := defaultSlowQueryThreshold
+	if thresholdStr := os.Getenv(slowQueryEnvName); len(thresholdStr) != 0 {
+		var err error
+		slowQueryThreshold, err = time.ParseDuration(thresholdStr)
+		if err != nil || slowQueryThreshold < 0 {
+			return nil, errors.Errorf("invalid slow query threshold: %s", thresholdStr)
+		}
+	}
+
+	return &pgConfig{
+		dsn:                dsn,
+		replicaDSNs:        replicaDSNs,
+		slowQueryThreshold: slowQueryThreshold,
+	}, nil
 }
 
 func (cfg *pgConfig) DSN() string {
 	return cfg.dsn
 }
+
+func (cfg *pgConfig) ReplicaDSNs() []string {
+	return cfg.replicaDSNs
+}
+
+func (cfg *pgConfig) SlowQueryThreshold() time.Duration {
+	return cfg.slowQueryThreshold
+}
@@ -0,0 +1,87 @@
+package env
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	redisAddrEnvName      = "REDIS_ADDR"
+	redisPasswordEnvName  = "REDIS_PASSWORD"
+	redisDBEnvName        = "REDIS_DB"
+	redisTTLEnvName       = "REDIS_TTL"
+	redisKeyPrefixEnvName = "REDIS_KEY_PREFIX"
+
+	defaultRedisTTL       = time.Hour
+	defaultRedisKeyPrefix = "order:"
+)
+
+type redisConfig struct {
+	addr      string
+	password  string
+	db        int
+	ttl       time.Duration
+	keyPrefix string
+}
+
+func NewRedisConfig() (config.RedisConfig, error) {
+	addr := os.Getenv(redisAddrEnvName)
+	if len(addr) == 0 {
+		return nil, errors.New("redis address not found")
+	}
+
+	db := 0
+	if dbStr := os.Getenv(redisDBEnvName); len(dbStr) != 0 {
+		var err error
+		db, err = strconv.Atoi(dbStr)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid redis db")
+		}
+	}
+
+	ttl := defaultRedisTTL
+	if ttlStr := os.Getenv(redisTTLEnvName); len(ttlStr) != 0 {
+		var err error
+		ttl, err = time.ParseDuration(ttlStr)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid redis ttl")
+		}
+	}
+
+	keyPrefix, ok := os.LookupEnv(redisKeyPrefixEnvName)
+	if !ok {
+		keyPrefix = defaultRedisKeyPrefix
+	}
+
+	return &redisConfig{
+		addr:      addr,
+		password:  os.Getenv(redisPasswordEnvName),
+		db:        db,
+		ttl:       ttl,
+		keyPrefix: keyPrefix,
+	}, nil
+}
+
+func (cfg *redisConfig) Addr() string {
+	return cfg.addr
+}
+
+func (cfg *redisConfig) Password() string {
+	return cfg.password
+}
+
+func (cfg *redisConfig) DB() int {
+	return cfg.db
+}
+
+func (cfg *redisConfig) TTL() time.Duration {
+	return cfg.ttl
+}
+
+func (cfg *redisConfig) KeyPrefix() string {
+	return cfg.keyPrefix
+}
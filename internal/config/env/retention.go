@@ -0,0 +1,87 @@
+package env
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	retentionMaxAgeEnvName     = "RETENTION_MAX_AGE"
+	retentionArchiveDirEnvName = "RETENTION_ARCHIVE_DIR"
+	retentionIntervalEnvName   = "RETENTION_INTERVAL"
+	partitionsAheadEnvName     = "PARTITIONS_AHEAD"
+
+	defaultRetentionArchiveDir = "./archive"
+	defaultRetentionInterval   = 24 * time.Hour
+	defaultPartitionsAhead     = 3
+)
+
+type retentionConfig struct {
+	maxAge          time.Duration
+	archiveDir      string
+	interval        time.Duration
+	partitionsAhead int
+}
+
+// NewRetentionConfig reads the retention settings. Without RETENTION_MAX_AGE
+// orders are kept forever and only future partitions are created.
+func NewRetentionConfig() (config.RetentionConfig, error) {
+	var maxAge time.Duration
+	if maxAgeStr := os.Getenv(retentionMaxAgeEnvName); len(maxAgeStr) != 0 {
+		var err error
+		maxAge, err = time.ParseDuration(maxAgeStr)
+		if err != nil || maxAge < 0 {
+			return nil, errors.Errorf("invalid retention max age: %s", maxAgeStr)
+		}
+	}
+
+	archiveDir := os.Getenv(retentionArchiveDirEnvName)
+	if len(archiveDir) == 0 {
+		archiveDir = defaultRetentionArchiveDir
+	}
+
+	interval := defaultRetentionInterval
+	if intervalStr := os.Getenv(retentionIntervalEnvName); len(intervalStr) != 0 {
+		var err error
+		interval, err = time.ParseDuration(intervalStr)
+		if err != nil || interval <= 0 {
+			return nil, errors.Errorf("invalid retention interval: %s", intervalStr)
+		}
+	}
+
+	partitionsAhead := defaultPartitionsAhead
+	if aheadStr := os.Getenv(partitionsAheadEnvName); len(aheadStr) != 0 {
+		var err error
+		partitionsAhead, err = strconv.Atoi(aheadStr)
+		if err != nil || partitionsAhead < 0 {
+			return nil, errors.Errorf("invalid partitions ahead: %s", aheadStr)
+		}
+	}
+
+	return &retentionConfig{
+		maxAge:          maxAge,
+		archiveDir:      archiveDir,
+		interval:        interval,
+		partitionsAhead: partitionsAhead,
+	}, nil
+}
+
+func (cfg *retentionConfig) MaxAge() time.Duration {
+	return cfg.maxAge
+}
+
+func (cfg *retentionConfig) ArchiveDir() string {
+	return cfg.archiveDir
+}
+
+func (cfg *retentionConfig) Interval() time.Duration {
+	return cfg.interval
+}
+
+func (cfg *retentionConfig) PartitionsAhead() int {
+	return cfg.partitionsAhead
+}
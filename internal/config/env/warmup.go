@@ -0,0 +1,107 @@
+package env
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	warmUpStrategyEnvName         = "WARMUP_STRATEGY"
+	warmUpLimitEnvName            = "WARMUP_LIMIT"
+	warmUpCustomersEnvName        = "WARMUP_CUSTOMERS"
+	warmUpChunkSizeEnvName        = "WARMUP_CHUNK_SIZE"
+	accessLogFlushIntervalEnvName = "ACCESS_LOG_FLUSH_INTERVAL"
+
+	defaultWarmUpLimit            = 100
+	defaultWarmUpChunkSize        = 500
+	defaultAccessLogFlushInterval = 30 * time.Second
+)
+
+type warmUpConfig struct {
+	strategy               string
+	limit                  int
+	customerIDs            []string
+	chunkSize              int
+	accessLogFlushInterval time.Duration
+}
+
+func NewWarmUpConfig() (config.WarmUpConfig, error) {
+	strategy := os.Getenv(warmUpStrategyEnvName)
+	if len(strategy) == 0 {
+		strategy = config.WarmUpStrategyRecent
+	}
+
+	switch strategy {
+	case config.WarmUpStrategyRecent, config.WarmUpStrategyMostRead, config.WarmUpStrategyCustomers, config.WarmUpStrategyNone:
+	default:
+		return nil, errors.Errorf("unknown warm-up strategy: %s", strategy)
+	}
+
+	limit := defaultWarmUpLimit
+	if limitStr := os.Getenv(warmUpLimitEnvName); len(limitStr) != 0 {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			return nil, errors.Errorf("invalid warm-up limit: %s", limitStr)
+		}
+	}
+
+	var customerIDs []string
+	if customersStr := os.Getenv(warmUpCustomersEnvName); len(customersStr) != 0 {
+		for _, customerID := range strings.Split(customersStr, ",") {
+			if customerID = strings.TrimSpace(customerID); len(customerID) != 0 {
+				customerIDs = append(customerIDs, customerID)
+			}
+		}
+	}
+	if strategy == config.WarmUpStrategyCustomers && len(customerIDs) == 0 {
+		return nil, errors.New("warm-up customers not found")
+	}
+
+	chunkSize, err := chunkSizeFromEnv(warmUpChunkSizeEnvName, defaultWarmUpChunkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	flushInterval := defaultAccessLogFlushInterval
+	if intervalStr := os.Getenv(accessLogFlushIntervalEnvName); len(intervalStr) != 0 {
+		var err error
+		flushInterval, err = time.ParseDuration(intervalStr)
+		if err != nil || flushInterval <= 0 {
+			return nil, errors.Errorf("invalid access log flush interval: %s", intervalStr)
+		}
+	}
+
+	return &warmUpConfig{
+		strategy:               strategy,
+		limit:                  limit,
+		customerIDs:            customerIDs,
+		chunkSize:              chunkSize,
+		accessLogFlushInterval: flushInterval,
+	}, nil
+}
+
+func (cfg *warmUpConfig) Strategy() string {
+	return cfg.strategy
+}
+
+func (cfg *warmUpConfig) Limit() int {
+	return cfg.limit
+}
+
+func (cfg *warmUpConfig) CustomerIDs() []string {
+	return cfg.customerIDs
+}
+
+func (cfg *warmUpConfig) ChunkSize() int {
+	return cfg.chunkSize
+}
+
+func (cfg *warmUpConfig) AccessLogFlushInterval() time.Duration {
+	return cfg.accessLogFlushInterval
+}
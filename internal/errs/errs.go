@@ -0,0 +1,79 @@
+package errs
+
+import "errors"
+
+// Code tells what kind of failure an error is. The API reports it to clients
+// and picks the HTTP status by it.
+type Code string
+
+const (
+	CodeNotFound         Code = "not_found"
+	CodeInvalidArgument  Code = "invalid_argument"
+	CodeConflict         Code = "conflict"
+	CodeUnauthenticated  Code = "unauthenticated"
+	CodeUnavailable      Code = "unavailable"
+	CodeCanceled         Code = "canceled"
+	CodeDeadlineExceeded Code = "deadline_exceeded"
+	CodeInternal         Code = "internal"
+)
+
+// Error is a domain error with a code.
+type Error struct {
+	code    Code
+	message string
+	cause   error
+}
+
+func New(code Code, message string) *Error {
+	return &Error{code: code, message: message}
+}
+
+// Wrap gives err a code. errors.Is and errors.As still see err.
+func Wrap(code Code, message string, err error) *Error {
+	return &Error{code: code, message: message, cause: err}
+}
+
+func NotFound(message string) *Error {
+	return New(CodeNotFound, message)
+}
+
+func InvalidArgument(message string) *Error {
+	return New(CodeInvalidArgument, message)
+}
+
+func Conflict(message string) *Error {
+	return New(CodeConflict, message)
+}
+
+func Unauthenticated(message string) *Error {
+	return New(CodeUnauthenticated, message)
+}
+
+func Unavailable(message string) *Error {
+	return New(CodeUnavailable, message)
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.message + ": " + e.cause.Error()
+	}
+	return e.message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+func (e *Error) Code() Code {
+	return e.code
+}
+
+// CodeOf returns the code of the first Error in the chain of err, or
+// CodeInternal if there is none.
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return CodeInternal
+}
@@ -0,0 +1,26 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOf(t *testing.T) {
+	notFound := NotFound("order not found")
+
+	assert.Equal(t, CodeNotFound, CodeOf(fmt.Errorf("failed to get order: %w", notFound)))
+	assert.Equal(t, CodeInternal, CodeOf(errors.New("boom")))
+	assert.Equal(t, CodeInternal, CodeOf(nil))
+}
+
+func TestWrap_KeepsCause(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := Wrap(CodeUnavailable, "failed to query order", cause)
+
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, CodeUnavailable, CodeOf(err))
+	assert.Equal(t, "failed to query order: connection refused", err.Error())
+}
@@ -0,0 +1,20 @@
+package model
+
+type CacheKey struct {
+	Key string `json:"key"`
+	Age string `json:"age"`
+}
+
+type CacheEntry struct {
+	Key           string `json:"key"`
+	Value         *Order `json:"value"`
+	InDB          bool   `json:"in_db"`
+	DiffersFromDB bool   `json:"differs_from_db"`
+}
+
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+}
@@ -1,24 +1,52 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 )
 
 type Order struct {
-	OrderUID          string    `json:"order_uid"`
-	TrackNumber       string    `json:"track_number"`
-	Entry             string    `json:"entry"`
-	Delivery          Delivery  `json:"delivery"`
-	Payment           Payment   `json:"payment"`
-	Items             []Item    `json:"items"`
-	Locale            string    `json:"locale"`
-	InternalSignature string    `json:"internal_signature"`
-	CustomerID        string    `json:"customer_id"`
-	DeliveryService   string    `json:"delivery_service"`
-	ShardKey          string    `json:"shardkey"`
-	SmID              int       `json:"sm_id"`
-	DateCreated       time.Time `json:"date_created"`
-	OofShard          string    `json:"oof_shard"`
+	OrderUID          string         `json:"order_uid"`
+	TrackNumber       string         `json:"track_number"`
+	Entry             string         `json:"entry"`
+	Delivery          Delivery       `json:"delivery"`
+	Payment           Payment        `json:"payment"`
+	Items             []Item         `json:"items"`
+	Locale            string         `json:"locale"`
+	InternalSignature string         `json:"internal_signature"`
+	CustomerID        string         `json:"customer_id"`
+	DeliveryService   string         `json:"delivery_service"`
+	ShardKey          string         `json:"shardkey"`
+	SmID              int            `json:"sm_id"`
+	DateCreated       time.Time      `json:"date_created"`
+	OofShard          string         `json:"oof_shard"`
+	Status            OrderStatus    `json:"status,omitempty"`
+	Timeline          []StatusChange `json:"timeline,omitempty"`
+}
+
+// UnmarshalJSON sets the currency of the payment on all amounts of the order.
+func (o *Order) UnmarshalJSON(data []byte) error {
+	type order Order
+	err := json.Unmarshal(data, (*order)(o))
+	if err != nil {
+		return err
+	}
+
+	o.SetCurrency(o.Payment.Currency)
+
+	return nil
+}
+
+// SetCurrency sets the currency of all amounts of the order. The whole order
+// is paid in one currency, which JSON and the database keep on the payment.
+func (o *Order) SetCurrency(currency string) {
+	o.Payment.Amount.Currency = currency
+	o.Payment.DeliveryCost.Currency = currency
+	o.Payment.CustomFee.Currency = currency
+	for i := range o.Items {
+		o.Items[i].Price.Currency = currency
+		o.Items[i].TotalPrice.Currency = currency
+	}
 }
 
 type Delivery struct {
@@ -32,28 +60,28 @@ type Delivery struct {
 }
 
 type Payment struct {
-	Transaction  string  `json:"transaction"`
-	RequestID    string  `json:"request"`
-	Currency     string  `json:"currency"`
-	Provider     string  `json:"provider"`
-	Amount       float64 `json:"amount"`
-	PaymentDt    int64   `json:"payment_dt"`
-	Bank         string  `json:"bank"`
-	DeliveryCost float64 `json:"delivery_cost"`
-	GoodsTotal   int     `json:"goods_total"`
-	CustomFee    float64 `json:"custom_fee"`
+	Transaction  string `json:"transaction"`
+	RequestID    string `json:"request"`
+	Currency     string `json:"currency"`
+	Provider     string `json:"provider"`
+	Amount       Money  `json:"amount"`
+	PaymentDt    int64  `json:"payment_dt"`
+	Bank         string `json:"bank"`
+	DeliveryCost Money  `json:"delivery_cost"`
+	GoodsTotal   int    `json:"goods_total"`
+	CustomFee    Money  `json:"custom_fee"`
 }
 
 type Item struct {
-	ChrtID      int64   `json:"chrt_id"`
-	TrackNumber string  `json:"track_number"`
-	Price       float64 `json:"price"`
-	Rid         string  `json:"rid"`
-	Name        string  `json:"name"`
-	Sale        int     `json:"sale"`
-	Size        string  `json:"size"`
-	TotalPrice  float64 `json:"total_price"`
-	NmID        int64   `json:"nm_id"`
-	Brand       string  `json:"brand"`
-	Status      int     `json:"status"`
+	ChrtID      int64      `json:"chrt_id"`
+	TrackNumber string     `json:"track_number"`
+	Price       Money      `json:"price"`
+	Rid         string     `json:"rid"`
+	Name        string     `json:"name"`
+	Sale        int        `json:"sale"`
+	Size        string     `json:"size"`
+	TotalPrice  Money      `json:"total_price"`
+	NmID        int64      `json:"nm_id"`
+	Brand       string     `json:"brand"`
+	Status      ItemStatus `json:"status"`
 }
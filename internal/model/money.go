@@ -0,0 +1,176 @@
+package model
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
+)
+
+// minorUnits is the number of minor units in one major unit. Every currency
+// orders come in has two decimal places.
+const minorUnits = 100
+
+// maxExponent bounds exponents like 1e2, which big.Rat would otherwise expand
+// into numbers of any size.
+const maxExponent = 30
+
+var ErrInvalidMoney = errs.InvalidArgument("invalid money amount")
+
+var decimalPattern = regexp.MustCompile(`^[+-]?(?:\d+\.?\d*|\.\d+)(?:[eE]([+-]?\d+))?$`)
+
+// Money is an exact amount in minor units (kopecks, cents) of Currency. It is
+// written to JSON and Postgres as a decimal number of major units, so amounts
+// round-trip without the rounding errors of float64.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses a decimal number of major units, e.g. "18.17" or "1e2".
+// Amounts finer than a minor unit are rejected rather than rounded.
+func ParseMoney(s string, currency string) (Money, error) {
+	r, err := parseMinorUnits(s)
+	if err != nil {
+		return Money{}, err
+	}
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	return NewMoney(r.Num().Int64(), currency), nil
+}
+
+// ParseMoneyRounded is ParseMoney for amounts that may be finer than a minor
+// unit, like prices computed in float by producers. They are rounded half to
+// even, e.g. "1.005" to 1.00 and "1.015" to 1.02.
+func ParseMoneyRounded(s string, currency string) (Money, error) {
+	r, err := parseMinorUnits(s)
+	if err != nil {
+		return Money{}, err
+	}
+
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	// quo is truncated towards zero; rem carries the sign of the amount.
+	twiceRem := new(big.Int).Abs(rem)
+	twiceRem.Lsh(twiceRem, 1)
+	switch twiceRem.Cmp(r.Denom()) {
+	case 1:
+		quo.Add(quo, big.NewInt(int64(rem.Sign())))
+	case 0:
+		if quo.Bit(0) == 1 {
+			quo.Add(quo, big.NewInt(int64(rem.Sign())))
+		}
+	}
+	if !quo.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	return NewMoney(quo.Int64(), currency), nil
+}
+
+// parseMinorUnits parses a decimal number of major units into an exact
+// number of minor units, which may have a fraction.
+func parseMinorUnits(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	match := decimalPattern.FindStringSubmatch(s)
+	if match == nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	if len(match[1]) != 0 {
+		exp, err := strconv.Atoi(match[1])
+		if err != nil || exp > maxExponent || exp < -maxExponent {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+		}
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	return r.Mul(r, big.NewRat(minorUnits, 1)), nil
+}
+
+// String formats the amount in major units without trailing zeros.
+func (m Money) String() string {
+	sign := ""
+	amount := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		amount = uint64(-m.Amount)
+	}
+
+	s := sign + strconv.FormatUint(amount/minorUnits, 10)
+	if fraction := amount % minorUnits; fraction != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%02d", fraction), "0")
+	}
+
+	return s
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a number or a string and rounds it to minor units,
+// so prices sent as floats are not dropped. The currency is kept, since JSON
+// carries it separately from the amounts.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	s := string(data)
+	if len(data) != 0 && data[0] == '"' {
+		err := json.Unmarshal(data, &s)
+		if err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseMoneyRounded(s, m.Currency)
+	if err != nil {
+		return err
+	}
+	m.Amount = parsed.Amount
+
+	return nil
+}
+
+// Scan reads a Postgres decimal, which database/sql passes as text.
+func (m *Money) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	default:
+		return fmt.Errorf("cannot scan %T into money", src)
+	}
+
+	parsed, err := ParseMoney(s, m.Currency)
+	if err != nil {
+		return err
+	}
+	m.Amount = parsed.Amount
+
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	for s, amount := range map[string]int64{
+		"1817":     181700,
+		"18.17":    1817,
+		"0.1":      10,
+		"-3.50":    -350,
+		"1.817e3":  181700,
+		"  42.00 ": 4200,
+	} {
+		m, err := ParseMoney(s, "RUB")
+		require.NoError(t, err, s)
+		assert.Equal(t, NewMoney(amount, "RUB"), m, s)
+	}
+
+	for _, s := range []string{"", "abc", "1.005", "1/2", "0x10", "1e100000000", "99999999999999999999"} {
+		_, err := ParseMoney(s, "RUB")
+		assert.ErrorIs(t, err, ErrInvalidMoney, s)
+	}
+}
+
+func TestParseMoneyRounded(t *testing.T) {
+	for s, amount := range map[string]int64{
+		"18.17":   1817,
+		"1.005":   100,
+		"1.015":   102,
+		"1.0051":  101,
+		"-1.005":  -100,
+		"-1.015":  -102,
+		"0.004":   0,
+		"317.445": 31744,
+		"1e-3":    0,
+	} {
+		m, err := ParseMoneyRounded(s, "RUB")
+		require.NoError(t, err, s)
+		assert.Equal(t, NewMoney(amount, "RUB"), m, s)
+	}
+
+	for _, s := range []string{"", "abc", "1e100000000", "99999999999999999999"} {
+		_, err := ParseMoneyRounded(s, "RUB")
+		assert.ErrorIs(t, err, ErrInvalidMoney, s)
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "1817", NewMoney(181700, "").String())
+	assert.Equal(t, "18.17", NewMoney(1817, "").String())
+	assert.Equal(t, "18.1", NewMoney(1810, "").String())
+	assert.Equal(t, "-0.05", NewMoney(-5, "").String())
+}
+
+func TestMoney_JSONRoundTrip(t *testing.T) {
+	var payment Payment
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": 0.3, "delivery_cost": "0.1", "custom_fee": 0}`), &payment))
+	assert.Equal(t, int64(30), payment.Amount.Amount)
+	assert.Equal(t, int64(10), payment.DeliveryCost.Amount)
+
+	data, err := json.Marshal(payment)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"amount":0.3,`)
+	assert.Contains(t, string(data), `"delivery_cost":0.1,`)
+}
+
+func TestMoney_UnmarshalJSONRoundsFloats(t *testing.T) {
+	var item Item
+	require.NoError(t, json.Unmarshal([]byte(`{"price": 453.499999999, "total_price": "317.445"}`), &item))
+	assert.Equal(t, int64(45350), item.Price.Amount)
+	assert.Equal(t, int64(31744), item.TotalPrice.Amount)
+}
+
+func TestOrder_UnmarshalJSONSetsCurrency(t *testing.T) {
+	var order Order
+	err := json.Unmarshal([]byte(`{
+		"payment": {"currency": "USD", "amount": 1817},
+		"items": [{"price": 453.5, "total_price": 317.45}]
+	}`), &order)
+	require.NoError(t, err)
+
+	assert.Equal(t, NewMoney(181700, "USD"), order.Payment.Amount)
+	assert.Equal(t, NewMoney(45350, "USD"), order.Items[0].Price)
+	assert.Equal(t, NewMoney(31745, "USD"), order.Items[0].TotalPrice)
+}
+
+func TestMoney_PostgresNumeric(t *testing.T) {
+	m := pgtype.NewMap()
+	for _, format := range []int16{pgtype.BinaryFormatCode, pgtype.TextFormatCode} {
+		buf, err := m.Encode(pgtype.NumericOID, format, NewMoney(181750, "RUB"), nil)
+		require.NoError(t, err)
+
+		scanned := Money{Currency: "RUB"}
+		require.NoError(t, m.Scan(pgtype.NumericOID, format, buf, &scanned))
+		assert.Equal(t, NewMoney(181750, "RUB"), scanned)
+	}
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
+)
+
+var ErrInvalidCursor = errs.InvalidArgument("invalid cursor")
+
+type OrderFilter struct {
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	Locale          string
+	Bank            string
+	Provider        string
+	Brand           string
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+	Limit           int
+}
+
+// OrderCursor points at the last order of a page. Orders are listed newest
+// first, ordered by (date_created, order_uid), so the next page starts right
+// after it.
+type OrderCursor struct {
+	DateCreated time.Time `json:"d"`
+	OrderUID    string    `json:"u"`
+}
+
+type OrderPage struct {
+	Orders     []*Order `json:"orders"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+func (c *OrderCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeOrderCursor(s string) (*OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &OrderCursor{}
+	err = json.Unmarshal(data, cursor)
+	if err != nil || len(cursor.OrderUID) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
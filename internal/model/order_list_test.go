@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderCursor_RoundTrip(t *testing.T) {
+	cursor := &OrderCursor{
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 123456000, time.UTC),
+		OrderUID:    "b563feb7b2b84b6test",
+	}
+
+	decoded, err := DecodeOrderCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, cursor.DateCreated.Equal(decoded.DateCreated))
+	assert.Equal(t, cursor.OrderUID, decoded.OrderUID)
+}
+
+func TestDecodeOrderCursor_Invalid(t *testing.T) {
+	for _, s := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		_, err := DecodeOrderCursor(s)
+		assert.ErrorIs(t, err, ErrInvalidCursor, s)
+	}
+}
@@ -0,0 +1,22 @@
+package model
+
+import "github.com/biryanim/wb_tech_L0/internal/errs"
+
+var ErrEmptySearchQuery = errs.InvalidArgument("search query is empty")
+
+type OrderSearchQuery struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+type OrderSearchResult struct {
+	OrderUID  string  `json:"order_uid"`
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
+
+type OrderSearchPage struct {
+	Results    []*OrderSearchResult `json:"results"`
+	NextOffset *int                 `json:"next_offset,omitempty"`
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// OrderSnapshot is an immutable serialized order. It is what the caches
+// hold, so callers can't change a cached order through a shared pointer.
+type OrderSnapshot struct {
+	data     []byte
+	etag     string
+	loadedAt time.Time
+}
+
+type orderSnapshotJSON struct {
+	LoadedAt time.Time       `json:"loaded_at"`
+	Order    json.RawMessage `json:"order"`
+}
+
+func NewOrderSnapshot(order *Order) (*OrderSnapshot, error) {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return nil, err
+	}
+
+	return newOrderSnapshot(data, time.Now()), nil
+}
+
+func newOrderSnapshot(data []byte, loadedAt time.Time) *OrderSnapshot {
+	sum := sha256.Sum256(data)
+
+	return &OrderSnapshot{
+		data:     data,
+		etag:     `"` + hex.EncodeToString(sum[:16]) + `"`,
+		loadedAt: loadedAt,
+	}
+}
+
+func (s *OrderSnapshot) ETag() string {
+	return s.etag
+}
+
+// LoadedAt is the time the order was read from the DB or received from Kafka.
+func (s *OrderSnapshot) LoadedAt() time.Time {
+	return s.loadedAt
+}
+
+// Order decodes a new copy of the order that the caller is free to modify.
+func (s *OrderSnapshot) Order() (*Order, error) {
+	order := &Order{}
+	err := json.Unmarshal(s.data, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (s *OrderSnapshot) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(s.data)
+	return int64(n), err
+}
+
+func (s *OrderSnapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(orderSnapshotJSON{
+		LoadedAt: s.loadedAt,
+		Order:    s.data,
+	})
+}
+
+// UnmarshalJSON also accepts a bare order, as written before snapshots kept
+// their load time. Such snapshots get a zero LoadedAt.
+func (s *OrderSnapshot) UnmarshalJSON(data []byte) error {
+	var snapshot orderSnapshotJSON
+	err := json.Unmarshal(data, &snapshot)
+	if err != nil {
+		return err
+	}
+	if len(snapshot.Order) == 0 {
+		snapshot = orderSnapshotJSON{Order: data}
+	}
+
+	var order Order
+	err = json.Unmarshal(snapshot.Order, &order)
+	if err != nil {
+		return err
+	}
+
+	*s = *newOrderSnapshot(bytes.Clone(snapshot.Order), snapshot.LoadedAt)
+	return nil
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderSnapshot_OrderReturnsCopies(t *testing.T) {
+	snapshot, err := NewOrderSnapshot(&Order{OrderUID: "uid1", Items: []Item{{Name: "Mascaras"}}})
+	require.NoError(t, err)
+
+	first, err := snapshot.Order()
+	require.NoError(t, err)
+	first.Delivery.Name = "masked"
+	first.Items[0].Name = "masked"
+
+	second, err := snapshot.Order()
+	require.NoError(t, err)
+	assert.Equal(t, "", second.Delivery.Name)
+	assert.Equal(t, "Mascaras", second.Items[0].Name)
+}
+
+func TestOrderSnapshot_JSONRoundTripKeepsETag(t *testing.T) {
+	snapshot, err := NewOrderSnapshot(&Order{OrderUID: "uid1"})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	restored := &OrderSnapshot{}
+	require.NoError(t, json.Unmarshal(data, restored))
+
+	var buf bytes.Buffer
+	_, err = restored.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.ETag(), restored.ETag())
+	assert.True(t, snapshot.LoadedAt().Equal(restored.LoadedAt()))
+
+	var written Order
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &written))
+	assert.Equal(t, "uid1", written.OrderUID)
+}
+
+func TestOrderSnapshot_UnmarshalBareOrder(t *testing.T) {
+	restored := &OrderSnapshot{}
+	require.NoError(t, json.Unmarshal([]byte(`{"order_uid":"uid1"}`), restored))
+
+	order, err := restored.Order()
+	require.NoError(t, err)
+	assert.Equal(t, "uid1", order.OrderUID)
+	assert.True(t, restored.LoadedAt().IsZero())
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// OrderVersion is the state of an order right after the writes of one
+// transaction. Source tells where they came from, e.g. the Kafka message
+// that caused them.
+type OrderVersion struct {
+	Version   int       `json:"version"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+	Order     *Order    `json:"order"`
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusCreated   OrderStatus = "created"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusAssembled OrderStatus = "assembled"
+	OrderStatusShipped   OrderStatus = "shipped"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusReturned  OrderStatus = "returned"
+)
+
+var (
+	ErrUnknownStatus     = errs.InvalidArgument("unknown order status")
+	ErrInvalidTransition = errs.Conflict("invalid order status transition")
+)
+
+// orderTransitions lists the statuses an order may move to from each status.
+// Cancelled and returned orders are final.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusCreated:   {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusAssembled, OrderStatusCancelled},
+	OrderStatusAssembled: {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:   {OrderStatusDelivered, OrderStatusReturned},
+	OrderStatusDelivered: {OrderStatusReturned},
+	OrderStatusCancelled: nil,
+	OrderStatusReturned:  nil,
+}
+
+func (s OrderStatus) Valid() bool {
+	_, ok := orderTransitions[s]
+	return ok
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ItemStatus is the numeric status code producers send with every item. The
+// codes follow the order lifecycle; items move along when their order does.
+type ItemStatus int
+
+const (
+	ItemStatusCreated   ItemStatus = 200
+	ItemStatusPaid      ItemStatus = 201
+	ItemStatusAssembled ItemStatus = 202
+	ItemStatusShipped   ItemStatus = 203
+	ItemStatusDelivered ItemStatus = 204
+	ItemStatusCancelled ItemStatus = 205
+	ItemStatusReturned  ItemStatus = 206
+)
+
+var itemStatuses = map[OrderStatus]ItemStatus{
+	OrderStatusCreated:   ItemStatusCreated,
+	OrderStatusPaid:      ItemStatusPaid,
+	OrderStatusAssembled: ItemStatusAssembled,
+	OrderStatusShipped:   ItemStatusShipped,
+	OrderStatusDelivered: ItemStatusDelivered,
+	OrderStatusCancelled: ItemStatusCancelled,
+	OrderStatusReturned:  ItemStatusReturned,
+}
+
+// itemTransitions mirrors orderTransitions.
+var itemTransitions = map[ItemStatus][]ItemStatus{
+	ItemStatusCreated:   {ItemStatusPaid, ItemStatusCancelled},
+	ItemStatusPaid:      {ItemStatusAssembled, ItemStatusCancelled},
+	ItemStatusAssembled: {ItemStatusShipped, ItemStatusCancelled},
+	ItemStatusShipped:   {ItemStatusDelivered, ItemStatusReturned},
+	ItemStatusDelivered: {ItemStatusReturned},
+	ItemStatusCancelled: nil,
+	ItemStatusReturned:  nil,
+}
+
+// ItemStatus returns the status items of an order in status s have.
+func (s OrderStatus) ItemStatus() ItemStatus {
+	return itemStatuses[s]
+}
+
+func (s ItemStatus) Valid() bool {
+	_, ok := itemTransitions[s]
+	return ok
+}
+
+func (s ItemStatus) CanTransitionTo(next ItemStatus) bool {
+	for _, allowed := range itemTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ItemStatusesBefore lists the statuses an item may move to next from.
+// Items with unknown codes are never moved.
+func ItemStatusesBefore(next ItemStatus) []ItemStatus {
+	var statuses []ItemStatus
+	for status := range itemTransitions {
+		if status.CanTransitionTo(next) {
+			statuses = append(statuses, status)
+		}
+	}
+	slices.Sort(statuses)
+
+	return statuses
+}
+
+// StatusChange is one entry of the order timeline. From is empty for the
+// initial status.
+type StatusChange struct {
+	From      OrderStatus `json:"from,omitempty"`
+	To        OrderStatus `json:"to"`
+	Reason    string      `json:"reason,omitempty"`
+	ChangedAt time.Time   `json:"changed_at"`
+}
+
+// StatusEvent is a status change published to the order status topic.
+type StatusEvent struct {
+	OrderUID  string      `json:"order_uid"`
+	Status    OrderStatus `json:"status"`
+	Reason    string      `json:"reason"`
+	ChangedAt time.Time   `json:"changed_at"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, OrderStatusCreated.CanTransitionTo(OrderStatusPaid))
+	assert.True(t, OrderStatusAssembled.CanTransitionTo(OrderStatusCancelled))
+	assert.True(t, OrderStatusDelivered.CanTransitionTo(OrderStatusReturned))
+
+	assert.False(t, OrderStatusCreated.CanTransitionTo(OrderStatusShipped))
+	assert.False(t, OrderStatusShipped.CanTransitionTo(OrderStatusCancelled))
+	assert.False(t, OrderStatusCancelled.CanTransitionTo(OrderStatusPaid))
+	assert.False(t, OrderStatusPaid.CanTransitionTo(OrderStatusPaid))
+}
+
+func TestOrderStatus_Valid(t *testing.T) {
+	assert.True(t, OrderStatusReturned.Valid())
+	assert.False(t, OrderStatus("lost").Valid())
+	assert.False(t, OrderStatus("").Valid())
+}
+
+func TestItemStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, ItemStatusCreated.CanTransitionTo(ItemStatusPaid))
+	assert.True(t, ItemStatusShipped.CanTransitionTo(ItemStatusReturned))
+
+	assert.False(t, ItemStatusCreated.CanTransitionTo(ItemStatusShipped))
+	assert.False(t, ItemStatusCancelled.CanTransitionTo(ItemStatusPaid))
+	assert.False(t, ItemStatus(0).CanTransitionTo(ItemStatusPaid))
+}
+
+func TestOrderStatus_ItemStatus(t *testing.T) {
+	for status := range orderTransitions {
+		assert.True(t, status.ItemStatus().Valid(), status)
+	}
+}
+
+func TestItemStatusesBefore(t *testing.T) {
+	assert.Equal(t, []ItemStatus{ItemStatusCreated, ItemStatusPaid, ItemStatusAssembled}, ItemStatusesBefore(ItemStatusCancelled))
+	assert.Equal(t, []ItemStatus{ItemStatusShipped, ItemStatusDelivered}, ItemStatusesBefore(ItemStatusReturned))
+	assert.Empty(t, ItemStatusesBefore(ItemStatusCreated))
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+const (
+	WarmUpStatePending = "pending"
+	WarmUpStateRunning = "running"
+	WarmUpStateDone    = "done"
+	WarmUpStateFailed  = "failed"
+	WarmUpStateSkipped = "skipped"
+)
+
+type WarmUpProgress struct {
+	Strategy   string     `json:"strategy"`
+	State      string     `json:"state"`
+	Total      int        `json:"total"`
+	Loaded     int        `json:"loaded"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
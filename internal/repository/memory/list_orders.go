@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/biryanim/wb_tech_L0/internal/model"
+)
+
+// ListOrders returns order aggregates matching the filter, newest first.
+// With a cursor, the listing continues right after the order it points at.
+func (r *repo) ListOrders(ctx context.Context, filter *model.OrderFilter, cursor *model.OrderCursor) ([]*model.Order, error) {
+	orders := make([]*model.Order, 0)
+	for _, rec := range r.read(ctx).orders {
+		if rec.delivery == nil || rec.payment == nil {
+			continue
+		}
+
+		order := rec.fullOrder()
+		if !matchesFilter(order, filter) {
+			continue
+		}
+		if cursor != nil && !byDateCreatedDesc(&model.Order{
+			OrderUID:    cursor.OrderUID,
+			DateCreated: cursor.DateCreated,
+		}, order) {
+			continue
+		}
+
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return byDateCreatedDesc(orders[i], orders[j])
+	})
+
+	return orders[:min(filter.Limit, len(orders))], nil
+}
+
+func matchesFilter(order *model.Order, filter *model.OrderFilter) bool {
+	switch {
+	case len(filter.CustomerID) != 0 && order.CustomerID != filter.CustomerID,
+		len(filter.TrackNumber) != 0 && order.TrackNumber != filter.TrackNumber,
+		len(filter.DeliveryService) != 0 && order.DeliveryService != filter.DeliveryService,
+		len(filter.Locale) != 0 && order.Locale != filter.Locale,
+		len(filter.Bank) != 0 && order.Payment.Bank != filter.Bank,
+		len(filter.Provider) != 0 && order.Payment.Provider != filter.Provider,
+		filter.CreatedFrom != nil && order.DateCreated.Before(*filter.CreatedFrom),
+		filter.CreatedTo != nil && !order.DateCreated.Before(*filter.CreatedTo):
+		return false
+	}
+
+	if len(filter.Brand) != 0 {
+		return slices.ContainsFunc(order.Items, func(item model.Item) bool {
+			return item.Brand == filter.Brand
+		})
+	}
+
+	return true
+}
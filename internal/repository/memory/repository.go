@@ -0,0 +1,549 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	def "github.com/biryanim/wb_tech_L0/internal/repository"
+)
+
+var _ def.OrderRepository = (*repo)(nil)
+
+// repo keeps orders in memory. A state is never changed once it is
+// committed: writes go to a copy that replaces the committed state as a
+// whole, so readers outside a transaction never see half of a write.
+type repo struct {
+	mu    sync.RWMutex
+	state *state
+
+	// writeMu serializes transactions and the writes made outside of them.
+	writeMu sync.Mutex
+	txIDs   atomic.Uint64
+	now     func() time.Time
+}
+
+// orderRecord holds the rows of one order. Records are shared between
+// states and are replaced rather than modified.
+type orderRecord struct {
+	order    model.Order
+	delivery *model.Delivery
+	payment  *model.Payment
+	items    []model.Item
+	timeline []model.StatusChange
+	versions []orderVersion
+}
+
+type orderVersion struct {
+	version   int
+	source    string
+	createdAt time.Time
+	data      []byte
+	txID      uint64
+}
+
+type orderReads struct {
+	count      int
+	lastReadAt time.Time
+}
+
+type state struct {
+	orders map[string]*orderRecord
+	reads  map[string]orderReads
+}
+
+func NewRepository() *repo {
+	return &repo{
+		state: &state{
+			orders: make(map[string]*orderRecord),
+			reads:  make(map[string]orderReads),
+		},
+		now: time.Now,
+	}
+}
+
+func (r *repo) CreateOrder(ctx context.Context, order *model.Order) (string, error) {
+	err := r.write(ctx, func(s *state) error {
+		if _, ok := s.orders[order.OrderUID]; ok {
+			return errs.Conflict(fmt.Sprintf("failed to insert order: order %s already exists", order.OrderUID))
+		}
+
+		s.orders[order.OrderUID] = &orderRecord{order: model.Order{
+			OrderUID:          order.OrderUID,
+			TrackNumber:       order.TrackNumber,
+			Entry:             order.Entry,
+			Locale:            order.Locale,
+			InternalSignature: order.InternalSignature,
+			CustomerID:        order.CustomerID,
+			DeliveryService:   order.DeliveryService,
+			ShardKey:          order.ShardKey,
+			SmID:              order.SmID,
+			DateCreated:       order.DateCreated.UTC(),
+			OofShard:          order.OofShard,
+			Status:            model.OrderStatusCreated,
+		}}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return order.OrderUID, nil
+}
+
+func (r *repo) CreateDelivery(ctx context.Context, orderID string, delivery *model.Delivery) (string, error) {
+	err := r.writeOrder(ctx, orderID, func(rec *orderRecord) error {
+		if rec.delivery != nil {
+			return errs.Conflict(fmt.Sprintf("failed to insert delivery: delivery of order %s already exists", orderID))
+		}
+
+		d := *delivery
+		rec.delivery = &d
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return orderID, nil
+}
+
+func (r *repo) CreatePayment(ctx context.Context, orderID string, payment *model.Payment) (string, error) {
+	err := r.writeOrder(ctx, orderID, func(rec *orderRecord) error {
+		if rec.payment != nil {
+			return errs.Conflict(fmt.Sprintf("failed to insert payment: payment of order %s already exists", orderID))
+		}
+
+		p := *payment
+		rec.payment = &p
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return orderID, nil
+}
+
+func (r *repo) CreateItem(ctx context.Context, orderID string, item *model.Item) error {
+	return r.CreateItems(ctx, orderID, []model.Item{*item})
+}
+
+func (r *repo) CreateItems(ctx context.Context, orderID string, items []model.Item) error {
+	return r.writeOrder(ctx, orderID, func(rec *orderRecord) error {
+		rec.items = append(slices.Clone(rec.items), items...)
+		return nil
+	})
+}
+
+func (r *repo) CreateStatusChange(ctx context.Context, orderID string, change *model.StatusChange) error {
+	return r.writeOrder(ctx, orderID, func(rec *orderRecord) error {
+		c := *change
+		c.ChangedAt = c.ChangedAt.UTC()
+		rec.timeline = append(slices.Clone(rec.timeline), c)
+		sort.SliceStable(rec.timeline, func(i, j int) bool {
+			return rec.timeline[i].ChangedAt.Before(rec.timeline[j].ChangedAt)
+		})
+		return nil
+	})
+}
+
+func (r *repo) GetOrder(ctx context.Context, orderID string) (*model.Order, error) {
+	rec, ok := r.read(ctx).orders[orderID]
+	if !ok {
+		return nil, def.ErrOrderNotFound
+	}
+
+	order := rec.order
+	order.Status = ""
+
+	return &order, nil
+}
+
+func (r *repo) GetDelivery(ctx context.Context, orderID string) (*model.Delivery, error) {
+	rec, ok := r.read(ctx).orders[orderID]
+	if !ok || rec.delivery == nil {
+		return nil, fmt.Errorf("failed to query delivery: %w", def.ErrOrderNotFound)
+	}
+
+	delivery := *rec.delivery
+	return &delivery, nil
+}
+
+func (r *repo) GetPayment(ctx context.Context, orderID string) (*model.Payment, error) {
+	rec, ok := r.read(ctx).orders[orderID]
+	if !ok || rec.payment == nil {
+		return nil, fmt.Errorf("failed to query payment: %w", def.ErrOrderNotFound)
+	}
+
+	payment := *rec.payment
+	return &payment, nil
+}
+
+func (r *repo) ListItems(ctx context.Context, orderID string) ([]*model.Item, error) {
+	rec, ok := r.read(ctx).orders[orderID]
+	if !ok {
+		return nil, nil
+	}
+
+	var items []*model.Item
+	for _, item := range rec.items {
+		items = append(items, &item)
+	}
+
+	return items, nil
+}
+
+func (r *repo) GetFullOrder(ctx context.Context, orderID string) (*model.Order, error) {
+	return r.read(ctx).fullOrder(orderID)
+}
+
+// GetFullOrders skips unknown and repeated IDs and returns the orders in the
+// order of orderIDs.
+func (r *repo) GetFullOrders(ctx context.Context, orderIDs []string) ([]*model.Order, error) {
+	if len(orderIDs) == 0 {
+		return nil, nil
+	}
+
+	s := r.read(ctx)
+	seen := make(map[string]struct{}, len(orderIDs))
+
+	orders := make([]*model.Order, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		if _, ok := seen[orderID]; ok {
+			continue
+		}
+		seen[orderID] = struct{}{}
+
+		order, err := s.fullOrder(orderID)
+		if err != nil {
+			continue
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+func (r *repo) GetOrderStatus(ctx context.Context, orderID string) (model.OrderStatus, error) {
+	rec, ok := r.read(ctx).orders[orderID]
+	if !ok {
+		return "", def.ErrOrderNotFound
+	}
+
+	return rec.order.Status, nil
+}
+
+func (r *repo) UpdateOrderStatus(ctx context.Context, orderID string, status model.OrderStatus) error {
+	return r.writeOrder(ctx, orderID, func(rec *orderRecord) error {
+		rec.order.Status = status
+		return nil
+	})
+}
+
+func (r *repo) UpdateItemStatuses(ctx context.Context, orderID string, from []model.ItemStatus, to model.ItemStatus) error {
+	return r.writeOrder(ctx, orderID, func(rec *orderRecord) error {
+		rec.items = slices.Clone(rec.items)
+		for i := range rec.items {
+			if slices.Contains(from, rec.items[i].Status) {
+				rec.items[i].Status = to
+			}
+		}
+		return nil
+	})
+}
+
+func (r *repo) RecordOrderVersion(ctx context.Context, orderID string) error {
+	version := r.newVersion(ctx)
+	return r.write(ctx, func(s *state) error {
+		return s.recordVersion(orderID, version)
+	})
+}
+
+func (r *repo) ListOrderVersions(ctx context.Context, orderID string) ([]*model.OrderVersion, error) {
+	versions := make([]*model.OrderVersion, 0)
+	rec, ok := r.read(ctx).orders[orderID]
+	if !ok {
+		return versions, nil
+	}
+
+	for _, v := range rec.versions {
+		version, err := v.model()
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// GetOrderVersionAt returns the latest version created at or before t.
+func (r *repo) GetOrderVersionAt(ctx context.Context, orderID string, t time.Time) (*model.OrderVersion, error) {
+	rec, ok := r.read(ctx).orders[orderID]
+	if !ok {
+		return nil, def.ErrOrderNotFound
+	}
+
+	for i := len(rec.versions) - 1; i >= 0; i-- {
+		if !rec.versions[i].createdAt.After(t) {
+			return rec.versions[i].model()
+		}
+	}
+
+	return nil, def.ErrOrderNotFound
+}
+
+func (r *repo) ListOrdersByLastAdded(ctx context.Context, limit int) ([]*model.Order, error) {
+	return r.listOrders(ctx, limit, nil, byDateCreatedDesc)
+}
+
+func (r *repo) ListMostReadOrders(ctx context.Context, limit int) ([]*model.Order, error) {
+	reads := r.read(ctx).reads
+	return r.listOrders(ctx, limit,
+		func(order *model.Order) bool {
+			_, ok := reads[order.OrderUID]
+			return ok
+		},
+		func(a, b *model.Order) bool {
+			ra, rb := reads[a.OrderUID], reads[b.OrderUID]
+			if ra.count != rb.count {
+				return ra.count > rb.count
+			}
+			return ra.lastReadAt.After(rb.lastReadAt)
+		},
+	)
+}
+
+func (r *repo) ListOrdersByCustomers(ctx context.Context, customerIDs []string, limit int) ([]*model.Order, error) {
+	return r.listOrders(ctx, limit,
+		func(order *model.Order) bool {
+			return slices.Contains(customerIDs, order.CustomerID)
+		},
+		byDateCreatedDesc,
+	)
+}
+
+func (r *repo) ListOrderIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	ids := make([]string, 0, limit)
+	for orderID := range r.read(ctx).orders {
+		if orderID > afterID {
+			ids = append(ids, orderID)
+		}
+	}
+	slices.Sort(ids)
+
+	return ids[:min(limit, len(ids))], nil
+}
+
+func (r *repo) RecordReads(ctx context.Context, reads map[string]int, readAt time.Time) error {
+	if len(reads) == 0 {
+		return nil
+	}
+
+	return r.write(ctx, func(s *state) error {
+		for orderID, count := range reads {
+			s.reads[orderID] = orderReads{
+				count:      s.reads[orderID].count + count,
+				lastReadAt: readAt,
+			}
+		}
+		return nil
+	})
+}
+
+// read returns the state of the transaction in ctx, or the committed state.
+func (r *repo) read(ctx context.Context) *state {
+	if tx, ok := ctx.Value(txKey).(*tx); ok {
+		return tx.state
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.state
+}
+
+// write applies fn to the state of the transaction in ctx. Outside of a
+// transaction fn gets a copy of the committed state, which is committed if
+// fn succeeds.
+func (r *repo) write(ctx context.Context, fn func(s *state) error) error {
+	if tx, ok := ctx.Value(txKey).(*tx); ok {
+		if tx.readOnly {
+			return errReadOnly
+		}
+		return fn(tx.state)
+	}
+
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	s := r.read(ctx).clone()
+	err := fn(s)
+	if err != nil {
+		return err
+	}
+	r.commit(s)
+
+	return nil
+}
+
+// writeOrder changes the record of the order with fn.
+func (r *repo) writeOrder(ctx context.Context, orderID string, fn func(rec *orderRecord) error) error {
+	return r.write(ctx, func(s *state) error {
+		return s.update(orderID, fn)
+	})
+}
+
+// newVersion returns a version recorded with ctx, without its number and
+// data. Versions recorded outside of a transaction get a transaction of
+// their own.
+func (r *repo) newVersion(ctx context.Context) orderVersion {
+	version := orderVersion{
+		source:    def.SourceOf(ctx),
+		createdAt: r.now().UTC(),
+	}
+	if tx, ok := ctx.Value(txKey).(*tx); ok {
+		version.txID = tx.id
+	} else {
+		version.txID = r.txIDs.Add(1)
+	}
+
+	return version
+}
+
+func (r *repo) commit(s *state) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state = s
+}
+
+func (r *repo) listOrders(
+	ctx context.Context,
+	limit int,
+	match func(order *model.Order) bool,
+	less func(a, b *model.Order) bool,
+) ([]*model.Order, error) {
+	orders := make([]*model.Order, 0)
+	for _, rec := range r.read(ctx).orders {
+		order := rec.order
+		order.Status = ""
+		if match == nil || match(&order) {
+			orders = append(orders, &order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return less(orders[i], orders[j])
+	})
+
+	return orders[:min(limit, len(orders))], nil
+}
+
+func byDateCreatedDesc(a, b *model.Order) bool {
+	if !a.DateCreated.Equal(b.DateCreated) {
+		return a.DateCreated.After(b.DateCreated)
+	}
+	return a.OrderUID > b.OrderUID
+}
+
+func (s *state) clone() *state {
+	return &state{
+		orders: maps.Clone(s.orders),
+		reads:  maps.Clone(s.reads),
+	}
+}
+
+// update replaces the record of the order with a copy changed by fn.
+func (s *state) update(orderID string, fn func(rec *orderRecord) error) error {
+	old, ok := s.orders[orderID]
+	if !ok {
+		return def.ErrOrderNotFound
+	}
+
+	rec := *old
+	err := fn(&rec)
+	if err != nil {
+		return err
+	}
+	s.orders[orderID] = &rec
+
+	return nil
+}
+
+// recordVersion stores the order as JSON, like the pg repository does, so
+// versions read back the same way from both. A later version of the same
+// transaction replaces the data of the earlier one.
+func (s *state) recordVersion(orderID string, version orderVersion) error {
+	order, err := s.fullOrder(orderID)
+	if err != nil {
+		return err
+	}
+
+	version.data, err = json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order: %w", err)
+	}
+
+	return s.update(orderID, func(rec *orderRecord) error {
+		versions := slices.Clone(rec.versions)
+		if n := len(versions); n != 0 && versions[n-1].txID == version.txID {
+			versions[n-1].data = version.data
+		} else {
+			version.version = n + 1
+			versions = append(versions, version)
+		}
+		rec.versions = versions
+		return nil
+	})
+}
+
+// fullOrder assembles the aggregate like the joins of the pg repository: an
+// order without its delivery or payment has them empty.
+func (s *state) fullOrder(orderID string) (*model.Order, error) {
+	rec, ok := s.orders[orderID]
+	if !ok {
+		return nil, def.ErrOrderNotFound
+	}
+
+	return rec.fullOrder(), nil
+}
+
+func (rec *orderRecord) fullOrder() *model.Order {
+	order := rec.order
+	if rec.delivery != nil {
+		order.Delivery = *rec.delivery
+	}
+	if rec.payment != nil {
+		order.Payment = *rec.payment
+	}
+	order.Items = slices.Clone(rec.items)
+	order.Timeline = slices.Clone(rec.timeline)
+	order.SetCurrency(order.Payment.Currency)
+
+	return &order
+}
+
+func (v orderVersion) model() (*model.OrderVersion, error) {
+	version := &model.OrderVersion{
+		Version:   v.version,
+		Source:    v.source,
+		CreatedAt: v.createdAt,
+	}
+
+	err := json.Unmarshal(v.data, &version.Order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal order: %w", err)
+	}
+
+	return version, nil
+}
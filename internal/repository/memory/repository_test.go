@@ -0,0 +1,317 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("test error")
+
+func createOrder(ctx context.Context, r *repo, orderID string, dateCreated time.Time) error {
+	_, err := r.CreateOrder(ctx, &model.Order{OrderUID: orderID, CustomerID: "customer", DateCreated: dateCreated})
+	if err != nil {
+		return err
+	}
+	_, err = r.CreateDelivery(ctx, orderID, &model.Delivery{Name: "Ivan Petrov", City: "Kazan"})
+	if err != nil {
+		return err
+	}
+	_, err = r.CreatePayment(ctx, orderID, &model.Payment{Transaction: orderID, Bank: "alpha"})
+	if err != nil {
+		return err
+	}
+
+	err = r.CreateItem(ctx, orderID, &model.Item{ChrtID: 1, Name: "Mascaras", Brand: "Vivienne Sabo"})
+	if err != nil {
+		return err
+	}
+
+	return r.RecordOrderVersion(ctx, orderID)
+}
+
+func TestTxManager_CommitsWrites(t *testing.T) {
+	r := NewRepository()
+	m := NewTxManager(r)
+	ctx := context.Background()
+
+	err := m.ReadCommited(repository.WithSource(ctx, "test"), func(ctx context.Context) error {
+		return createOrder(ctx, r, "uid1", time.Now())
+	})
+	require.NoError(t, err)
+
+	order, err := r.GetFullOrder(ctx, "uid1")
+	require.NoError(t, err)
+	assert.Equal(t, model.OrderStatusCreated, order.Status)
+	assert.Equal(t, "Ivan Petrov", order.Delivery.Name)
+	assert.Equal(t, "alpha", order.Payment.Bank)
+	assert.Len(t, order.Items, 1)
+
+	versions, err := r.ListOrderVersions(ctx, "uid1")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, 1, versions[0].Version)
+	assert.Equal(t, "test", versions[0].Source)
+	assert.Equal(t, "Ivan Petrov", versions[0].Order.Delivery.Name)
+	assert.Len(t, versions[0].Order.Items, 1)
+}
+
+func TestRepository_RecordsVersionsOutsideTransaction(t *testing.T) {
+	r := NewRepository()
+	ctx := context.Background()
+
+	require.NoError(t, createOrder(ctx, r, "uid1", time.Now()))
+	require.NoError(t, r.UpdateOrderStatus(ctx, "uid1", model.OrderStatusPaid))
+	require.NoError(t, r.RecordOrderVersion(ctx, "uid1"))
+
+	versions, err := r.ListOrderVersions(ctx, "uid1")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, repository.UnknownSource, versions[1].Source)
+	assert.Equal(t, model.OrderStatusCreated, versions[0].Order.Status)
+	assert.Equal(t, model.OrderStatusPaid, versions[1].Order.Status)
+}
+
+func TestTxManager_RecordsOneVersionPerTransaction(t *testing.T) {
+	r := NewRepository()
+	m := NewTxManager(r)
+	ctx := context.Background()
+
+	err := m.ReadCommited(ctx, func(ctx context.Context) error {
+		err := createOrder(ctx, r, "uid1", time.Now())
+		if err != nil {
+			return err
+		}
+		err = r.UpdateOrderStatus(ctx, "uid1", model.OrderStatusPaid)
+		if err != nil {
+			return err
+		}
+		return r.RecordOrderVersion(ctx, "uid1")
+	})
+	require.NoError(t, err)
+
+	versions, err := r.ListOrderVersions(ctx, "uid1")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, model.OrderStatusPaid, versions[0].Order.Status)
+}
+
+func TestTxManager_RollsBackOnError(t *testing.T) {
+	r := NewRepository()
+	m := NewTxManager(r)
+	ctx := context.Background()
+
+	err := m.ReadCommited(ctx, func(ctx context.Context) error {
+		err := createOrder(ctx, r, "uid1", time.Now())
+		if err != nil {
+			return err
+		}
+		return errTest
+	})
+	require.ErrorIs(t, err, errTest)
+
+	_, err = r.GetOrder(ctx, "uid1")
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+}
+
+func TestTxManager_RollsBackOnPanic(t *testing.T) {
+	r := NewRepository()
+	m := NewTxManager(r)
+	ctx := context.Background()
+
+	err := m.ReadCommited(ctx, func(ctx context.Context) error {
+		_, err := r.CreateOrder(ctx, &model.Order{OrderUID: "uid1"})
+		require.NoError(t, err)
+		panic("boom")
+	})
+	require.Error(t, err)
+
+	_, err = r.GetOrder(ctx, "uid1")
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+}
+
+func TestTxManager_HidesUncommittedWrites(t *testing.T) {
+	r := NewRepository()
+	m := NewTxManager(r)
+
+	err := m.ReadCommited(context.Background(), func(ctx context.Context) error {
+		_, err := r.CreateOrder(ctx, &model.Order{OrderUID: "uid1"})
+		require.NoError(t, err)
+
+		_, err = r.GetOrder(ctx, "uid1")
+		assert.NoError(t, err)
+		_, err = r.GetOrder(context.Background(), "uid1")
+		assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = r.GetOrder(context.Background(), "uid1")
+	assert.NoError(t, err)
+}
+
+func TestTxManager_NestedRollsBackToSavepoint(t *testing.T) {
+	r := NewRepository()
+	m := NewTxManager(r)
+	ctx := context.Background()
+
+	err := m.ReadCommited(ctx, func(ctx context.Context) error {
+		_, err := r.CreateOrder(ctx, &model.Order{OrderUID: "uid1"})
+		if err != nil {
+			return err
+		}
+
+		err = m.Nested(ctx, func(ctx context.Context) error {
+			_, err := r.CreateOrder(ctx, &model.Order{OrderUID: "uid2"})
+			if err != nil {
+				return err
+			}
+			return errTest
+		})
+		assert.ErrorIs(t, err, errTest)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = r.GetOrder(ctx, "uid1")
+	assert.NoError(t, err)
+	_, err = r.GetOrder(ctx, "uid2")
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+}
+
+func TestTxManager_ReadOnlyRejectsWrites(t *testing.T) {
+	r := NewRepository()
+	m := NewTxManager(r)
+
+	err := m.ReadOnly(context.Background(), func(ctx context.Context) error {
+		_, err := r.CreateOrder(ctx, &model.Order{OrderUID: "uid1"})
+		return err
+	})
+	require.ErrorIs(t, err, errReadOnly)
+}
+
+func TestTxManager_ReadOnlyRejectsNestedWriteTransaction(t *testing.T) {
+	r := NewRepository()
+	m := NewTxManager(r)
+
+	err := m.ReadOnly(context.Background(), func(ctx context.Context) error {
+		return m.ReadCommited(ctx, func(ctx context.Context) error { return nil })
+	})
+	require.ErrorIs(t, err, db.ErrIncompatibleTx)
+}
+
+func TestTxManager_ConcurrentTransactions(t *testing.T) {
+	r := NewRepository()
+	m := NewTxManager(r)
+	ctx := context.Background()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := m.ReadCommited(ctx, func(ctx context.Context) error {
+				return createOrder(ctx, r, fmt.Sprintf("uid%02d", i), time.Now())
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	ids, err := r.ListOrderIDs(ctx, "", 100)
+	require.NoError(t, err)
+	assert.Len(t, ids, 20)
+}
+
+func TestRepository_ListOrdersPagesNewestFirst(t *testing.T) {
+	r := NewRepository()
+	ctx := context.Background()
+	base := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+	for i, orderID := range []string{"uid1", "uid2", "uid3"} {
+		require.NoError(t, createOrder(ctx, r, orderID, base.Add(time.Duration(i)*time.Hour)))
+	}
+
+	orders, err := r.ListOrders(ctx, &model.OrderFilter{Bank: "alpha", Limit: 2}, nil)
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	assert.Equal(t, "uid3", orders[0].OrderUID)
+	assert.Equal(t, "uid2", orders[1].OrderUID)
+
+	orders, err = r.ListOrders(ctx, &model.OrderFilter{Limit: 2}, &model.OrderCursor{
+		DateCreated: orders[1].DateCreated,
+		OrderUID:    orders[1].OrderUID,
+	})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, "uid1", orders[0].OrderUID)
+
+	orders, err = r.ListOrders(ctx, &model.OrderFilter{Brand: "Nivea", Limit: 2}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, orders)
+}
+
+func TestRepository_SearchRanksWordsAboveFragments(t *testing.T) {
+	r := NewRepository()
+	ctx := context.Background()
+	require.NoError(t, createOrder(ctx, r, "uid1", time.Now()))
+	_, err := r.CreateOrder(ctx, &model.Order{OrderUID: "uid2", TrackNumber: "KAZANTIP"})
+	require.NoError(t, err)
+
+	results, err := r.Search(ctx, &model.OrderSearchQuery{Query: "kazan", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "uid1", results[0].OrderUID)
+	assert.Contains(t, results[0].Highlight, "<mark>Kazan</mark>")
+	assert.Equal(t, "uid2", results[1].OrderUID)
+	assert.Greater(t, results[0].Rank, results[1].Rank)
+}
+
+func TestRepository_GetFullOrderWithoutDeliveryAndPayment(t *testing.T) {
+	r := NewRepository()
+	ctx := context.Background()
+	_, err := r.CreateOrder(ctx, &model.Order{OrderUID: "uid1"})
+	require.NoError(t, err)
+
+	order, err := r.GetFullOrder(ctx, "uid1")
+	require.NoError(t, err)
+	assert.Equal(t, "uid1", order.OrderUID)
+	assert.Equal(t, model.Delivery{}, order.Delivery)
+}
+
+func TestRepository_GetFullOrdersKeepsInputOrder(t *testing.T) {
+	r := NewRepository()
+	ctx := context.Background()
+	for _, orderID := range []string{"uid1", "uid2", "uid3"} {
+		require.NoError(t, createOrder(ctx, r, orderID, time.Now()))
+	}
+
+	orders, err := r.GetFullOrders(ctx, []string{"uid3", "unknown", "uid1", "uid3", "uid2"})
+	require.NoError(t, err)
+	require.Len(t, orders, 3)
+	assert.Equal(t, "uid3", orders[0].OrderUID)
+	assert.Equal(t, "uid1", orders[1].OrderUID)
+	assert.Equal(t, "uid2", orders[2].OrderUID)
+}
+
+func TestRepository_SearchEscapesHighlight(t *testing.T) {
+	r := NewRepository()
+	ctx := context.Background()
+	_, err := r.CreateOrder(ctx, &model.Order{OrderUID: "uid1", TrackNumber: "<b>KAZAN</b>"})
+	require.NoError(t, err)
+
+	results, err := r.Search(ctx, &model.OrderSearchQuery{Query: "kazan", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "&lt;b&gt;<mark>KAZAN</mark>&lt;/b&gt;", results[0].Highlight)
+}
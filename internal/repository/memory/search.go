@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/biryanim/wb_tech_L0/internal/model"
+)
+
+// Search matches whole words and fragments of the same document the pg
+// search indexes. Word matches are ranked above fragment-only matches.
+func (r *repo) Search(ctx context.Context, query *model.OrderSearchQuery) ([]*model.OrderSearchResult, error) {
+	words := searchWords(query.Query)
+	fragment := strings.ToLower(query.Query)
+
+	results := make([]*model.OrderSearchResult, 0)
+	for orderID, rec := range r.read(ctx).orders {
+		document := searchDocument(rec)
+		documentWords := searchWords(document)
+
+		var matched []string
+		for _, word := range words {
+			if slices.Contains(documentWords, word) {
+				matched = append(matched, word)
+			}
+		}
+
+		rank := float64(len(matched))
+		if strings.Contains(strings.ToLower(document), fragment) {
+			rank += 0.5
+			if len(matched) == 0 {
+				matched = append(matched, fragment)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		results = append(results, &model.OrderSearchResult{
+			OrderUID:  orderID,
+			Rank:      rank,
+			Highlight: highlight(document, matched),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].OrderUID < results[j].OrderUID
+	})
+
+	if query.Offset >= len(results) {
+		return results[:0], nil
+	}
+	results = results[query.Offset:]
+
+	return results[:min(query.Limit, len(results))], nil
+}
+
+func searchDocument(rec *orderRecord) string {
+	parts := []string{rec.order.TrackNumber}
+	if rec.delivery != nil {
+		parts = append(parts,
+			rec.delivery.Name,
+			rec.delivery.City,
+			rec.delivery.Address,
+			rec.delivery.Region,
+			rec.delivery.Email,
+		)
+	}
+	for _, item := range rec.items {
+		parts = append(parts, item.Name, item.Brand)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func highlight(document string, terms []string) string {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+
+	// The document is escaped piece by piece, since escaping it first could
+	// break up matches.
+	re := regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+	var b strings.Builder
+	last := 0
+	for _, match := range re.FindAllStringIndex(document, -1) {
+		b.WriteString(html.EscapeString(document[last:match[0]]))
+		b.WriteString("<mark>" + html.EscapeString(document[match[0]:match[1]]) + "</mark>")
+		last = match[1]
+	}
+	b.WriteString(html.EscapeString(document[last:]))
+
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+)
+
+var errReadOnly = errors.New("cannot write in a read-only transaction")
+
+type txKeyType struct{}
+
+var txKey = txKeyType{}
+
+// tx works on its own copy of the state until it commits. Transactions run
+// one at a time, so every isolation level is effectively serializable.
+type tx struct {
+	id       uint64
+	state    *state
+	readOnly bool
+}
+
+type txManager struct {
+	repo *repo
+}
+
+// NewTxManager returns a transaction manager for the given repository.
+// Writes made inside a transaction must use the context passed to the
+// handler: a write with another context waits for the transaction to end.
+func NewTxManager(repo *repo) db.TxManager {
+	return &txManager{repo: repo}
+}
+
+func (m *txManager) ReadCommited(ctx context.Context, f db.Handler) error {
+	return m.transaction(ctx, false, f)
+}
+
+func (m *txManager) RepeatableRead(ctx context.Context, f db.Handler) error {
+	return m.transaction(ctx, false, f)
+}
+
+func (m *txManager) Serializable(ctx context.Context, f db.Handler) error {
+	return m.transaction(ctx, false, f)
+}
+
+func (m *txManager) ReadOnly(ctx context.Context, f db.Handler) error {
+	return m.transaction(ctx, true, f)
+}
+
+// Nested rolls back only the writes of f when ctx already holds a
+// transaction. Without one it behaves like ReadCommited.
+func (m *txManager) Nested(ctx context.Context, f db.Handler) error {
+	t, ok := ctx.Value(txKey).(*tx)
+	if !ok {
+		return m.ReadCommited(ctx, f)
+	}
+
+	savepoint := t.state.clone()
+	err := run(ctx, f)
+	if err != nil {
+		t.state = savepoint
+	}
+
+	return err
+}
+
+func (m *txManager) transaction(ctx context.Context, readOnly bool, f db.Handler) error {
+	if t, ok := ctx.Value(txKey).(*tx); ok {
+		if t.readOnly && !readOnly {
+			return fmt.Errorf("cannot write inside a read-only transaction: %w", db.ErrIncompatibleTx)
+		}
+		return f(ctx)
+	}
+
+	// A read-only transaction never changes its state, so it can share the
+	// committed one and does not wait for writers.
+	if readOnly {
+		return run(context.WithValue(ctx, txKey, &tx{state: m.repo.read(ctx), readOnly: true}), f)
+	}
+
+	m.repo.writeMu.Lock()
+	defer m.repo.writeMu.Unlock()
+
+	t := &tx{id: m.repo.txIDs.Add(1), state: m.repo.read(ctx).clone()}
+	err := run(context.WithValue(ctx, txKey, t), f)
+	if err != nil {
+		return err
+	}
+	m.repo.commit(t.state)
+
+	return nil
+}
+
+func run(ctx context.Context, f db.Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic recovered: %v", r)
+		}
+	}()
+
+	err = f(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to execute transaction: %w", err)
+	}
+
+	return nil
+}
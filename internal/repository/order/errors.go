@@ -0,0 +1,50 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	uniqueViolationCode    = "23505"
+	connectionFailureClass = "08"
+	adminShutdownCode      = "57P01"
+	tooManyConnectionsCode = "53300"
+)
+
+// dbError tells the callers what kind of failure err is: a duplicate row is
+// a conflict, a cancelled or expired context is reported as such, and a lost
+// connection or a timeout means the database is unavailable. Other errors are
+// internal.
+func dbError(err error, message string) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return errs.Wrap(errs.CodeCanceled, message, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return errs.Wrap(errs.CodeDeadlineExceeded, message, err)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == uniqueViolationCode:
+			return errs.Wrap(errs.CodeConflict, message, err)
+		case strings.HasPrefix(pgErr.Code, connectionFailureClass),
+			pgErr.Code == adminShutdownCode,
+			pgErr.Code == tooManyConnectionsCode:
+			return errs.Wrap(errs.CodeUnavailable, message, err)
+		}
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) || pgconn.Timeout(err) {
+		return errs.Wrap(errs.CodeUnavailable, message, err)
+	}
+
+	return fmt.Errorf("%s: %w", message, err)
+}
@@ -0,0 +1,27 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBError(t *testing.T) {
+	for err, code := range map[error]errs.Code{
+		&pgconn.PgError{Code: "23505"}:                    errs.CodeConflict,
+		&pgconn.PgError{Code: "08006"}:                    errs.CodeUnavailable,
+		fmt.Errorf("query: %w", context.DeadlineExceeded): errs.CodeDeadlineExceeded,
+		fmt.Errorf("query: %w", context.Canceled):         errs.CodeCanceled,
+		&pgconn.PgError{Code: "22P02"}:                    errs.CodeInternal,
+		errors.New("conn closed"):                         errs.CodeInternal,
+	} {
+		wrapped := dbError(err, "failed to insert order")
+		assert.Equal(t, code, errs.CodeOf(wrapped), err.Error())
+		assert.ErrorIs(t, wrapped, err)
+	}
+}
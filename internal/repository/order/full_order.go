@@ -0,0 +1,234 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	def "github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+const itemsJSONColumn = `COALESCE((
+	SELECT json_agg(json_build_object(
+		'chrt_id', i.chrt_id,
+		'track_number', i.track_number,
+		'price', i.price,
+		'rid', i.rid,
+		'name', i.name,
+		'sale', i.sale,
+		'size', i.size,
+		'total_price', i.total_price,
+		'nm_id', i.nm_id,
+		'brand', i.brand,
+		'status', i.status
+	) ORDER BY i.id)
+	FROM items i
+	WHERE i.order_uid = o.order_uid AND i.date_created = o.date_created
+), '[]'::json)`
+
+// Timestamps are stored without a time zone and read back as UTC.
+const timelineJSONColumn = `COALESCE((
+	SELECT json_agg(json_build_object(
+		'from', h.from_status,
+		'to', h.to_status,
+		'reason', h.reason,
+		'changed_at', h.changed_at AT TIME ZONE 'UTC'
+	) ORDER BY h.changed_at, h.id)
+	FROM order_status_history h
+	WHERE h.order_uid = o.order_uid
+), '[]'::json)`
+
+func (r *repo) GetFullOrder(ctx context.Context, orderID string) (*model.Order, error) {
+	query, args, err := r.selectFullOrders().
+		Where(squirrel.Eq{"o.order_uid": orderID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	order, err := scanFullOrder(r.db.DB().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, def.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "failed to query order")
+	}
+
+	return order, nil
+}
+
+// GetFullOrders loads the aggregates of all given orders with one query, in
+// the order of orderIDs. Unknown IDs are skipped, so the result may be
+// shorter than orderIDs.
+func (r *repo) GetFullOrders(ctx context.Context, orderIDs []string) ([]*model.Order, error) {
+	if len(orderIDs) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := r.selectFullOrders().
+		Where("o.order_uid = ANY(?)", orderIDs).
+		OrderByClause("array_position(?::text[], o.order_uid::text)", orderIDs).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	rows, err := r.db.ReplicaDB().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err, "failed to query orders")
+	}
+	defer rows.Close()
+
+	orders := make([]*model.Order, 0, len(orderIDs))
+	for rows.Next() {
+		order, err := scanFullOrder(rows)
+		if err != nil {
+			return nil, dbError(err, "failed to scan order")
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(err, "failed to query orders")
+	}
+
+	return orders, nil
+}
+
+func (r *repo) ListOrderIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	query, args, err := r.qb.
+		Select("order_uid").
+		From("orders").
+		Where(squirrel.Gt{"order_uid": afterID}).
+		OrderBy("order_uid").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	rows, err := r.db.ReplicaDB().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err, "failed to query order ids")
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0, limit)
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, dbError(err, "failed to scan order id")
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(err, "failed to query order ids")
+	}
+
+	return ids, nil
+}
+
+// selectFullOrders reads an order without a delivery or payment row with
+// those parts left empty, as reading them one by one did.
+func (r *repo) selectFullOrders() squirrel.SelectBuilder {
+	return r.qb.
+		Select(
+			"o.order_uid",
+			"o.track_number",
+			"o.entry",
+			"o.locale",
+			"o.internal_signature",
+			"o.customer_id",
+			"o.delivery_service",
+			"o.shardKey",
+			"o.sm_id",
+			"o.date_created",
+			"o.oof_shard",
+			"o.status",
+			"COALESCE(d.name, '')",
+			"COALESCE(d.phone, '')",
+			"COALESCE(d.zip, '')",
+			"COALESCE(d.city, '')",
+			"COALESCE(d.address, '')",
+			"COALESCE(d.region, '')",
+			"COALESCE(d.email, '')",
+			"COALESCE(p.transaction, '')",
+			"COALESCE(p.request_id, '')",
+			"COALESCE(p.currency, '')",
+			"COALESCE(p.provider, '')",
+			"COALESCE(p.amount, 0)",
+			"COALESCE(p.payment_dt, 0)",
+			"COALESCE(p.bank, '')",
+			"COALESCE(p.delivery_cost, 0)",
+			"COALESCE(p.goods_total, 0)",
+			"COALESCE(p.custom_fee, 0)",
+			itemsJSONColumn,
+			timelineJSONColumn,
+		).
+		From("orders o").
+		LeftJoin("deliveries d ON d.order_uid = o.order_uid AND d.date_created = o.date_created").
+		LeftJoin("payments p ON p.order_uid = o.order_uid AND p.date_created = o.date_created")
+}
+
+func scanFullOrder(row pgx.Row) (*model.Order, error) {
+	order := &model.Order{}
+	var items, timeline []byte
+	err := row.Scan(
+		&order.OrderUID,
+		&order.TrackNumber,
+		&order.Entry,
+		&order.Locale,
+		&order.InternalSignature,
+		&order.CustomerID,
+		&order.DeliveryService,
+		&order.ShardKey,
+		&order.SmID,
+		&order.DateCreated,
+		&order.OofShard,
+		&order.Status,
+		&order.Delivery.Name,
+		&order.Delivery.Phone,
+		&order.Delivery.Zip,
+		&order.Delivery.City,
+		&order.Delivery.Address,
+		&order.Delivery.Region,
+		&order.Delivery.Email,
+		&order.Payment.Transaction,
+		&order.Payment.RequestID,
+		&order.Payment.Currency,
+		&order.Payment.Provider,
+		&order.Payment.Amount,
+		&order.Payment.PaymentDt,
+		&order.Payment.Bank,
+		&order.Payment.DeliveryCost,
+		&order.Payment.GoodsTotal,
+		&order.Payment.CustomFee,
+		&items,
+		&timeline,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(items, &order.Items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal items: %w", err)
+	}
+	if len(order.Items) == 0 {
+		order.Items = nil
+	}
+
+	err = json.Unmarshal(timeline, &order.Timeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal timeline: %w", err)
+	}
+	if len(order.Timeline) == 0 {
+		order.Timeline = nil
+	}
+	order.SetCurrency(order.Payment.Currency)
+
+	return order, nil
+}
@@ -0,0 +1,85 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+)
+
+// ListOrders returns order aggregates matching the filter, newest first.
+// With a cursor, the listing continues right after the order it points at.
+func (r *repo) ListOrders(ctx context.Context, filter *model.OrderFilter, cursor *model.OrderCursor) ([]*model.Order, error) {
+	builder := r.selectFullOrders().
+		Where(orderFilterCond(filter)).
+		OrderBy("o.date_created DESC", "o.order_uid DESC").
+		Limit(uint64(filter.Limit))
+	if cursor != nil {
+		builder = builder.Where("(o.date_created, o.order_uid) < (?, ?)", cursor.DateCreated, cursor.OrderUID)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	rows, err := r.db.ReplicaDB().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err, "failed to query orders")
+	}
+	defer rows.Close()
+
+	orders := make([]*model.Order, 0, filter.Limit)
+	for rows.Next() {
+		order, err := scanFullOrder(rows)
+		if err != nil {
+			return nil, dbError(err, "failed to scan order")
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(err, "failed to query orders")
+	}
+
+	return orders, nil
+}
+
+func orderFilterCond(filter *model.OrderFilter) squirrel.And {
+	cond := squirrel.And{}
+	eq := squirrel.Eq{}
+	if len(filter.CustomerID) != 0 {
+		eq["o.customer_id"] = filter.CustomerID
+	}
+	if len(filter.TrackNumber) != 0 {
+		eq["o.track_number"] = filter.TrackNumber
+	}
+	if len(filter.DeliveryService) != 0 {
+		eq["o.delivery_service"] = filter.DeliveryService
+	}
+	if len(filter.Locale) != 0 {
+		eq["o.locale"] = filter.Locale
+	}
+	if len(filter.Bank) != 0 {
+		eq["p.bank"] = filter.Bank
+	}
+	if len(filter.Provider) != 0 {
+		eq["p.provider"] = filter.Provider
+	}
+	if len(eq) != 0 {
+		cond = append(cond, eq)
+	}
+	if filter.CreatedFrom != nil {
+		cond = append(cond, squirrel.GtOrEq{"o.date_created": *filter.CreatedFrom})
+	}
+	if filter.CreatedTo != nil {
+		cond = append(cond, squirrel.Lt{"o.date_created": *filter.CreatedTo})
+	}
+	if len(filter.Brand) != 0 {
+		cond = append(cond, squirrel.Expr(
+			"EXISTS (SELECT 1 FROM items bi WHERE bi.order_uid = o.order_uid AND bi.brand = ?)",
+			filter.Brand,
+		))
+	}
+
+	return cond
+}
@@ -0,0 +1,162 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	def "github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"strings"
+	"time"
+)
+
+const partitionMonthLayout = "2006_01"
+
+var _ def.PartitionRepository = (*repo)(nil)
+
+// partitionedTables are listed children first, the order they are dropped in.
+var partitionedTables = []string{"items", "payments", "deliveries", "orders"}
+
+// orderTables hold per-order data outside the partitions and are cleaned up
+// when a partition is dropped.
+var orderTables = []string{"order_reads", "order_search", "order_status_history", "order_uids"}
+
+// CreatePartitions creates the partitions of the months from from to to. The
+// months that have rows in the default partitions get partitions as well and
+// the rows are moved into them, so retention archives them like any other.
+func (r *repo) CreatePartitions(ctx context.Context, from, to time.Time) error {
+	_, err := r.db.DB().ExecContext(ctx, "SELECT create_order_partitions($1, $2)", from, to)
+	if err != nil {
+		return dbError(err, "failed to create partitions")
+	}
+
+	return nil
+}
+
+// ListPartitions returns the months of the existing order partitions,
+// oldest first. The default partition is not included.
+func (r *repo) ListPartitions(ctx context.Context) ([]time.Time, error) {
+	query, args, err := r.qb.
+		Select("c.relname").
+		From("pg_inherits i").
+		Join("pg_class c ON c.oid = i.inhrelid").
+		Join("pg_class p ON p.oid = i.inhparent").
+		Where(squirrel.Eq{"p.relname": "orders"}).
+		OrderBy("c.relname").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	rows, err := r.db.DB().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err, "failed to query partitions")
+	}
+	defer rows.Close()
+
+	months := make([]time.Time, 0)
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, dbError(err, "failed to scan partition")
+		}
+
+		month, err := time.Parse(partitionMonthLayout, strings.TrimPrefix(name, "orders_p"))
+		if err != nil {
+			continue
+		}
+		months = append(months, month)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(err, "failed to query partitions")
+	}
+
+	return months, nil
+}
+
+// LockPartition blocks writes to the partitions of the month until the end
+// of the transaction, so nothing is written between export and drop.
+func (r *repo) LockPartition(ctx context.Context, month time.Time) error {
+	names := make([]string, 0, len(partitionedTables))
+	for _, table := range partitionedTables {
+		names = append(names, partitionName(table, month))
+	}
+
+	_, err := r.db.DB().ExecContext(ctx, "LOCK TABLE "+strings.Join(names, ", ")+" IN SHARE MODE")
+	if err != nil {
+		return dbError(err, "failed to lock partition")
+	}
+
+	return nil
+}
+
+func (r *repo) ExportPartition(ctx context.Context, month time.Time, fn func(order *model.Order) error) error {
+	query, args, err := r.selectFullOrders().
+		Where(squirrel.GtOrEq{"o.date_created": month}).
+		Where(squirrel.Lt{"o.date_created": month.AddDate(0, 1, 0)}).
+		OrderBy("o.date_created", "o.order_uid").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	rows, err := r.db.DB().QueryContext(ctx, query, args...)
+	if err != nil {
+		return dbError(err, "failed to query orders")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		order, err := scanFullOrder(rows)
+		if err != nil {
+			return dbError(err, "failed to scan order")
+		}
+
+		err = fn(order)
+		if err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return dbError(err, "failed to query orders")
+	}
+
+	return nil
+}
+
+// DropPartition removes the orders of the month with everything that refers
+// to them and tells the replicas to drop them from their caches. Order
+// versions are an audit log and are kept.
+func (r *repo) DropPartition(ctx context.Context, month time.Time) error {
+	orders := partitionName("orders", month)
+
+	statements := make([]string, 0, len(orderTables)+1+2*len(partitionedTables))
+	for _, table := range orderTables {
+		statements = append(statements, fmt.Sprintf(
+			"DELETE FROM %s WHERE order_uid IN (SELECT order_uid FROM %s)", table, orders,
+		))
+	}
+	statements = append(statements, fmt.Sprintf("SELECT pg_notify('order_changed', order_uid) FROM %s", orders))
+	for _, table := range partitionedTables {
+		partition := partitionName(table, month)
+		statements = append(statements,
+			fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", table, partition),
+			fmt.Sprintf("DROP TABLE %s", partition),
+		)
+	}
+
+	for _, statement := range statements {
+		_, err := r.db.DB().ExecContext(ctx, statement)
+		if err != nil {
+			return dbError(err, "failed to drop partition")
+		}
+	}
+
+	return nil
+}
+
+func partitionName(table string, month time.Time) string {
+	return pgx.Identifier{table + "_p" + month.Format(partitionMonthLayout)}.Sanitize()
+}
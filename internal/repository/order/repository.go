@@ -2,15 +2,21 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/Masterminds/squirrel"
 	"github.com/biryanim/wb_tech_L0/internal/client/db"
 	"github.com/biryanim/wb_tech_L0/internal/model"
 	def "github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"time"
 )
 
 var _ def.OrderRepository = (*repo)(nil)
 
+// repo reads single orders from the primary, since they feed the cache and
+// must see orders right after ingestion. Listings, search and history can
+// tolerate replication lag and go to the replicas.
 type repo struct {
 	db db.Client
 	qb squirrel.StatementBuilderType
@@ -61,7 +67,7 @@ func (r *repo) CreateOrder(ctx context.Context, order *model.Order) (string, err
 	var id string
 	err = r.db.DB().QueryRowContext(ctx, query, args...).Scan(&id)
 	if err != nil {
-		return "", fmt.Errorf("failed to insert order: %w", err)
+		return "", dbError(err, "failed to insert order")
 	}
 
 	return id, nil
@@ -101,8 +107,11 @@ func (r *repo) GetOrder(ctx context.Context, orderID string) (*model.Order, erro
 		&order.DateCreated,
 		&order.OofShard,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, def.ErrOrderNotFound
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query order: %w", err)
+		return nil, dbError(err, "failed to query order")
 	}
 	order.OrderUID = orderID
 
@@ -114,6 +123,7 @@ func (r *repo) CreateDelivery(ctx context.Context, orderID string, delivery *mod
 		Insert("deliveries").
 		Columns(
 			"order_uid",
+			"date_created",
 			"name",
 			"phone",
 			"zip",
@@ -124,6 +134,7 @@ func (r *repo) CreateDelivery(ctx context.Context, orderID string, delivery *mod
 		).
 		Values(
 			orderID,
+			orderDateCreated(orderID),
 			delivery.Name,
 			delivery.Phone,
 			delivery.Zip,
@@ -140,7 +151,7 @@ func (r *repo) CreateDelivery(ctx context.Context, orderID string, delivery *mod
 	var id string
 	err = r.db.DB().QueryRowContext(ctx, query, args...).Scan(&id)
 	if err != nil {
-		return "", fmt.Errorf("failed to insert delivery: %w", err)
+		return "", dbError(err, "failed to insert delivery")
 	}
 
 	return id, nil
@@ -174,8 +185,11 @@ func (r *repo) GetDelivery(ctx context.Context, orderID string) (*model.Delivery
 		&delivery.Region,
 		&delivery.Email,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to query delivery: %w", def.ErrOrderNotFound)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query delivery: %w", err)
+		return nil, dbError(err, "failed to query delivery")
 	}
 
 	return &delivery, nil
@@ -186,6 +200,7 @@ func (r *repo) CreatePayment(ctx context.Context, orderID string, payment *model
 		Insert("payments").
 		Columns(
 			"order_uid",
+			"date_created",
 			"transaction",
 			"request_id",
 			"currency",
@@ -199,6 +214,7 @@ func (r *repo) CreatePayment(ctx context.Context, orderID string, payment *model
 		).
 		Values(
 			orderID,
+			orderDateCreated(orderID),
 			payment.Transaction,
 			payment.RequestID,
 			payment.Currency,
@@ -219,7 +235,7 @@ func (r *repo) CreatePayment(ctx context.Context, orderID string, payment *model
 	var id string
 	err = r.db.DB().QueryRowContext(ctx, query, args...).Scan(&id)
 	if err != nil {
-		return "", fmt.Errorf("failed to insert payment: %w", err)
+		return "", dbError(err, "failed to insert payment")
 	}
 
 	return id, nil
@@ -259,17 +275,35 @@ func (r *repo) GetPayment(ctx context.Context, orderID string) (*model.Payment,
 		&payment.GoodsTotal,
 		&payment.CustomFee,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to query payment: %w", def.ErrOrderNotFound)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query payment: %w", err)
+		return nil, dbError(err, "failed to query payment")
 	}
+	payment.Amount.Currency = payment.Currency
+	payment.DeliveryCost.Currency = payment.Currency
+	payment.CustomFee.Currency = payment.Currency
+
 	return &payment, nil
 }
 
 func (r *repo) CreateItem(ctx context.Context, orderID string, item *model.Item) error {
-	query, args, err := r.qb.
+	return r.CreateItems(ctx, orderID, []model.Item{*item})
+}
+
+// CreateItems inserts all items with one statement, so the search document
+// of the order is rebuilt once rather than once per item.
+func (r *repo) CreateItems(ctx context.Context, orderID string, items []model.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	builder := r.qb.
 		Insert("items").
 		Columns(
 			"order_uid",
+			"date_created",
 			"chrt_id",
 			"track_number",
 			"price",
@@ -281,9 +315,11 @@ func (r *repo) CreateItem(ctx context.Context, orderID string, item *model.Item)
 			"nm_id",
 			"brand",
 			"status",
-		).
-		Values(
+		)
+	for _, item := range items {
+		builder = builder.Values(
 			orderID,
+			orderDateCreated(orderID),
 			item.ChrtID,
 			item.TrackNumber,
 			item.Price,
@@ -295,15 +331,17 @@ func (r *repo) CreateItem(ctx context.Context, orderID string, item *model.Item)
 			item.NmID,
 			item.Brand,
 			item.Status,
-		).
-		ToSql()
+		)
+	}
+
+	query, args, err := builder.ToSql()
 	if err != nil {
 		return fmt.Errorf("failed to build insert query: %w", err)
 	}
 
 	_, err = r.db.DB().ExecContext(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to insert item: %w", err)
+		return dbError(err, "failed to insert items")
 	}
 
 	return nil
@@ -323,6 +361,7 @@ func (r *repo) ListItems(ctx context.Context, orderID string) ([]*model.Item, er
 			"nm_id",
 			"brand",
 			"status",
+			"(SELECT p.currency FROM payments p WHERE p.order_uid = items.order_uid)",
 		).
 		From("items").
 		Where(squirrel.Eq{"order_uid": orderID}).
@@ -333,13 +372,14 @@ func (r *repo) ListItems(ctx context.Context, orderID string) ([]*model.Item, er
 
 	rows, err := r.db.DB().QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query items: %w", err)
+		return nil, dbError(err, "failed to query items")
 	}
 	defer rows.Close()
 
 	var items []*model.Item
 	for rows.Next() {
 		item := &model.Item{}
+		var currency *string
 		err = rows.Scan(
 			&item.ChrtID,
 			&item.TrackNumber,
@@ -352,24 +392,88 @@ func (r *repo) ListItems(ctx context.Context, orderID string) ([]*model.Item, er
 			&item.NmID,
 			&item.Brand,
 			&item.Status,
+			&currency,
 		)
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to query items: %w", err)
+			return nil, dbError(err, "failed to query items")
+		}
+		if currency != nil {
+			item.Price.Currency = *currency
+			item.TotalPrice.Currency = *currency
 		}
 
 		items = append(items, item)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("failed to query items: %w", err)
+		return nil, dbError(err, "failed to query items")
 	}
 
 	return items, nil
 }
 
 func (r *repo) ListOrdersByLastAdded(ctx context.Context, limit int) ([]*model.Order, error) {
-	query, args, err := r.qb.
+	return r.listOrders(ctx, r.selectOrders().
+		OrderBy("date_created DESC").
+		Limit(uint64(limit)),
+	)
+}
+
+func (r *repo) ListMostReadOrders(ctx context.Context, limit int) ([]*model.Order, error) {
+	return r.listOrders(ctx, r.selectOrders().
+		Join("order_reads r USING (order_uid)").
+		OrderBy("r.read_count DESC", "r.last_read_at DESC").
+		Limit(uint64(limit)),
+	)
+}
+
+func (r *repo) ListOrdersByCustomers(ctx context.Context, customerIDs []string, limit int) ([]*model.Order, error) {
+	return r.listOrders(ctx, r.selectOrders().
+		Where(squirrel.Eq{"customer_id": customerIDs}).
+		OrderBy("date_created DESC").
+		Limit(uint64(limit)),
+	)
+}
+
+func (r *repo) RecordReads(ctx context.Context, reads map[string]int, readAt time.Time) error {
+	if len(reads) == 0 {
+		return nil
+	}
+
+	builder := r.qb.
+		Insert("order_reads").
+		Columns("order_uid", "read_count", "last_read_at")
+	for orderID, count := range reads {
+		builder = builder.Values(orderID, count, readAt)
+	}
+
+	query, args, err := builder.
+		Suffix("ON CONFLICT (order_uid) DO UPDATE SET " +
+			"read_count = order_reads.read_count + EXCLUDED.read_count, " +
+			"last_read_at = EXCLUDED.last_read_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %w", err)
+	}
+
+	_, err = r.db.DB().ExecContext(ctx, query, args...)
+	if err != nil {
+		return dbError(err, "failed to record order reads")
+	}
+
+	return nil
+}
+
+// orderDateCreated copies the partition key of the order into its child
+// rows, so they land in the partition of the same month. order_uids holds
+// one row per order, unlike the partitioned orders.
+func orderDateCreated(orderID string) squirrel.Sqlizer {
+	return squirrel.Expr("(SELECT date_created FROM order_uids WHERE order_uid = ?)", orderID)
+}
+
+func (r *repo) selectOrders() squirrel.SelectBuilder {
+	return r.qb.
 		Select(
 			"order_uid",
 			"track_number",
@@ -383,17 +487,18 @@ func (r *repo) ListOrdersByLastAdded(ctx context.Context, limit int) ([]*model.O
 			"date_created",
 			"oof_shard",
 		).
-		From("orders").
-		OrderBy("date_created").
-		Limit(uint64(limit)).
-		ToSql()
+		From("orders")
+}
+
+func (r *repo) listOrders(ctx context.Context, builder squirrel.SelectBuilder) ([]*model.Order, error) {
+	query, args, err := builder.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build select query: %w", err)
 	}
 
-	rows, err := r.db.DB().QueryContext(ctx, query, args...)
+	rows, err := r.db.ReplicaDB().QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query orders: %w", err)
+		return nil, dbError(err, "failed to query orders")
 	}
 	defer rows.Close()
 
@@ -414,13 +519,13 @@ func (r *repo) ListOrdersByLastAdded(ctx context.Context, limit int) ([]*model.O
 			&order.OofShard,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to query orders: %w", err)
+			return nil, dbError(err, "failed to query orders")
 		}
 
 		orders = append(orders, order)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("failed to query orders: %w", err)
+		return nil, dbError(err, "failed to query orders")
 	}
 
 	return orders, nil
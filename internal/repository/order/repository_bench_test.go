@@ -0,0 +1,85 @@
+package order
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db/pg"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+// The benchmarks need a migrated database, e.g.
+// TEST_PG_DSN="host=localhost port=5432 dbname=orders user=user password=password sslmode=disable"
+const testDSNEnv = "TEST_PG_DSN"
+
+const benchItems = 10
+
+func newBenchRepository(b *testing.B) (*repo, string) {
+	dsn := os.Getenv(testDSNEnv)
+	if len(dsn) == 0 {
+		b.Skipf("%s is not set", testDSNEnv)
+	}
+
+	ctx := context.Background()
+	client, err := pg.New(ctx, dsn)
+	require.NoError(b, err)
+	b.Cleanup(func() { client.Close() })
+
+	r := NewRepository(client)
+	orderID := "bench-" + time.Now().Format("20060102150405.000000000")
+
+	_, err = r.CreateOrder(ctx, &model.Order{
+		OrderUID:    orderID,
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Locale:      "en",
+		CustomerID:  "bench",
+		DateCreated: time.Now(),
+	})
+	require.NoError(b, err)
+	b.Cleanup(func() {
+		_, err := client.DB().ExecContext(ctx, "DELETE FROM orders WHERE order_uid = $1", orderID)
+		require.NoError(b, err)
+	})
+
+	_, err = r.CreateDelivery(ctx, orderID, &model.Delivery{Name: "Test Testov", City: "Kiryat Mozkin"})
+	require.NoError(b, err)
+	_, err = r.CreatePayment(ctx, orderID, &model.Payment{Transaction: orderID, Currency: "USD", Amount: model.NewMoney(181700, "USD")})
+	require.NoError(b, err)
+	for i := 0; i < benchItems; i++ {
+		err = r.CreateItem(ctx, orderID, &model.Item{ChrtID: int64(i), Name: "Mascaras", Price: model.NewMoney(45300, "USD")})
+		require.NoError(b, err)
+	}
+
+	return r, orderID
+}
+
+func BenchmarkGetOrderSeparateQueries(b *testing.B) {
+	r, orderID := newBenchRepository(b)
+	ctx := context.Background()
+
+	for b.Loop() {
+		_, err := r.GetOrder(ctx, orderID)
+		require.NoError(b, err)
+		_, err = r.GetDelivery(ctx, orderID)
+		require.NoError(b, err)
+		_, err = r.GetPayment(ctx, orderID)
+		require.NoError(b, err)
+		_, err = r.ListItems(ctx, orderID)
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkGetFullOrder(b *testing.B) {
+	r, orderID := newBenchRepository(b)
+	ctx := context.Background()
+
+	for b.Loop() {
+		order, err := r.GetFullOrder(ctx, orderID)
+		require.NoError(b, err)
+		require.Len(b, order.Items, benchItems)
+	}
+}
@@ -0,0 +1,76 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"html"
+	"strings"
+)
+
+// ts_headline marks matches with private use characters. The headline is
+// HTML-escaped before they are replaced with <mark> tags, so customer data
+// in it cannot inject markup.
+const (
+	markStart = "\uE000"
+	markStop  = "\uE001"
+)
+
+var searchHeadlineOptions = fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxFragments=3, MaxWords=15, MinWords=5`, markStart, markStop)
+
+var markReplacer = strings.NewReplacer(markStart, "<mark>", markStop, "</mark>")
+
+// Search matches whole words through the tsvector and word fragments through
+// the trigram index. Word matches are ranked above fragment-only matches.
+func (r *repo) Search(ctx context.Context, query *model.OrderSearchQuery) ([]*model.OrderSearchResult, error) {
+	pattern := "%" + escapeLike(query.Query) + "%"
+
+	sql, args, err := r.qb.
+		Select("s.order_uid").
+		Column("ts_rank(s.search_vector, q.query) + word_similarity(?, s.document) AS rank", query.Query).
+		Column("ts_headline('simple', s.document, q.query, ?)", searchHeadlineOptions).
+		From("order_search s").
+		JoinClause("CROSS JOIN websearch_to_tsquery('simple', ?) AS q(query)", query.Query).
+		Where(squirrel.Or{
+			squirrel.Expr("s.search_vector @@ q.query"),
+			squirrel.ILike{"s.document": pattern},
+		}).
+		OrderBy("rank DESC", "s.order_uid").
+		Limit(uint64(query.Limit)).
+		Offset(uint64(query.Offset)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	rows, err := r.db.ReplicaDB().QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, dbError(err, "failed to search orders")
+	}
+	defer rows.Close()
+
+	results := make([]*model.OrderSearchResult, 0, query.Limit)
+	for rows.Next() {
+		result := &model.OrderSearchResult{}
+		err = rows.Scan(&result.OrderUID, &result.Rank, &result.Highlight)
+		if err != nil {
+			return nil, dbError(err, "failed to scan search result")
+		}
+		result.Highlight = markHighlight(result.Highlight)
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(err, "failed to search orders")
+	}
+
+	return results, nil
+}
+
+func markHighlight(headline string) string {
+	return markReplacer.Replace(html.EscapeString(headline))
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,13 @@
+package order
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkHighlight(t *testing.T) {
+	headline := "Ivan <script>alert(1)</script> " + markStart + "Kazan" + markStop + " & co"
+
+	assert.Equal(t, "Ivan &lt;script&gt;alert(1)&lt;/script&gt; <mark>Kazan</mark> &amp; co", markHighlight(headline))
+}
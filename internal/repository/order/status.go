@@ -0,0 +1,100 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	def "github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetOrderStatus locks the order row until the end of the transaction, so
+// concurrent status changes of one order are applied one after another.
+func (r *repo) GetOrderStatus(ctx context.Context, orderID string) (model.OrderStatus, error) {
+	query, args, err := r.qb.
+		Select("status").
+		From("orders").
+		Where(squirrel.Eq{"order_uid": orderID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var status model.OrderStatus
+	err = r.db.DB().QueryRowContext(ctx, query, args...).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", def.ErrOrderNotFound
+	}
+	if err != nil {
+		return "", dbError(err, "failed to query order status")
+	}
+
+	return status, nil
+}
+
+func (r *repo) UpdateOrderStatus(ctx context.Context, orderID string, status model.OrderStatus) error {
+	query, args, err := r.qb.
+		Update("orders").
+		Set("status", status).
+		Where(squirrel.Eq{"order_uid": orderID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	tag, err := r.db.DB().ExecContext(ctx, query, args...)
+	if err != nil {
+		return dbError(err, "failed to update order status")
+	}
+	if tag.RowsAffected() == 0 {
+		return def.ErrOrderNotFound
+	}
+
+	return nil
+}
+
+// UpdateItemStatuses moves the items of the order that are in one of the
+// from statuses to status to.
+func (r *repo) UpdateItemStatuses(ctx context.Context, orderID string, from []model.ItemStatus, to model.ItemStatus) error {
+	query, args, err := r.qb.
+		Update("items").
+		Set("status", to).
+		Where(squirrel.Eq{"order_uid": orderID, "status": from}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %w", err)
+	}
+
+	_, err = r.db.DB().ExecContext(ctx, query, args...)
+	if err != nil {
+		return dbError(err, "failed to update item statuses")
+	}
+
+	return nil
+}
+
+func (r *repo) CreateStatusChange(ctx context.Context, orderID string, change *model.StatusChange) error {
+	var from *model.OrderStatus
+	if len(change.From) != 0 {
+		from = &change.From
+	}
+
+	query, args, err := r.qb.
+		Insert("order_status_history").
+		Columns("order_uid", "from_status", "to_status", "reason", "changed_at").
+		Values(orderID, from, change.To, change.Reason, change.ChangedAt).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %w", err)
+	}
+
+	_, err = r.db.DB().ExecContext(ctx, query, args...)
+	if err != nil {
+		return dbError(err, "failed to insert status change")
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	def "github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// RecordOrderVersion stores the state of the order as the version of the
+// current transaction. The order row is locked first, so concurrent
+// transactions number their versions one after another.
+func (r *repo) RecordOrderVersion(ctx context.Context, orderID string) error {
+	lockQuery, lockArgs, err := r.qb.
+		Select("1").
+		From("orders").
+		Where(squirrel.Eq{"order_uid": orderID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	var locked int
+	err = r.db.DB().QueryRowContext(ctx, lockQuery, lockArgs...).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return def.ErrOrderNotFound
+	}
+	if err != nil {
+		return dbError(err, "failed to lock order")
+	}
+
+	order, err := r.GetFullOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order: %w", err)
+	}
+
+	query, args, err := r.qb.
+		Insert("order_versions").
+		Columns("order_uid", "version", "source", "created_at", "data", "tx_id").
+		Select(r.qb.
+			Select().
+			Column("?", orderID).
+			Column("COALESCE(MAX(version), 0) + 1").
+			Column("?", def.SourceOf(ctx)).
+			Column("?::timestamp", time.Now().UTC()).
+			Column("?::jsonb", data).
+			Column("txid_current()").
+			From("order_versions").
+			Where(squirrel.Eq{"order_uid": orderID}),
+		).
+		Suffix("ON CONFLICT (order_uid, tx_id) DO UPDATE SET data = excluded.data").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %w", err)
+	}
+
+	_, err = r.db.DB().ExecContext(ctx, query, args...)
+	if err != nil {
+		return dbError(err, "failed to insert order version")
+	}
+
+	return nil
+}
+
+func (r *repo) ListOrderVersions(ctx context.Context, orderID string) ([]*model.OrderVersion, error) {
+	query, args, err := r.selectOrderVersions().
+		Where(squirrel.Eq{"order_uid": orderID}).
+		OrderBy("version").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	rows, err := r.db.ReplicaDB().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(err, "failed to query order versions")
+	}
+	defer rows.Close()
+
+	versions := make([]*model.OrderVersion, 0)
+	for rows.Next() {
+		version, err := scanOrderVersion(rows)
+		if err != nil {
+			return nil, dbError(err, "failed to scan order version")
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(err, "failed to query order versions")
+	}
+
+	return versions, nil
+}
+
+// GetOrderVersionAt returns the latest version created at or before t.
+func (r *repo) GetOrderVersionAt(ctx context.Context, orderID string, t time.Time) (*model.OrderVersion, error) {
+	query, args, err := r.selectOrderVersions().
+		Where(squirrel.Eq{"order_uid": orderID}).
+		Where(squirrel.LtOrEq{"created_at": t.UTC()}).
+		OrderBy("version DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	version, err := scanOrderVersion(r.db.ReplicaDB().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, def.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, dbError(err, "failed to query order version")
+	}
+
+	return version, nil
+}
+
+func (r *repo) selectOrderVersions() squirrel.SelectBuilder {
+	return r.qb.
+		Select("version", "source", "created_at", "data").
+		From("order_versions")
+}
+
+func scanOrderVersion(row pgx.Row) (*model.OrderVersion, error) {
+	version := &model.OrderVersion{}
+	var data []byte
+	err := row.Scan(&version.Version, &version.Source, &version.CreatedAt, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &version.Order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal order: %w", err)
+	}
+
+	return version, nil
+}
@@ -2,20 +2,74 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/biryanim/wb_tech_L0/internal/errs"
 	"github.com/biryanim/wb_tech_L0/internal/model"
 )
 
+var ErrOrderNotFound = errs.NotFound("order not found")
+
+// UnknownSource is the source of versions recorded without WithSource.
+const UnknownSource = "unknown"
+
+type sourceKey struct{}
+
+// WithSource sets where the writes made with ctx come from, e.g. the Kafka
+// message that caused them. Versions recorded with ctx are tagged with this
+// source.
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+func SourceOf(ctx context.Context) string {
+	if source, ok := ctx.Value(sourceKey{}).(string); ok {
+		return source
+	}
+	return UnknownSource
+}
+
 type OrderRepository interface {
 	CreateOrder(ctx context.Context, order *model.Order) (string, error)
 	CreateDelivery(ctx context.Context, orderID string, delivery *model.Delivery) (string, error)
 	CreatePayment(ctx context.Context, orderID string, payment *model.Payment) (string, error)
 	CreateItem(ctx context.Context, orderID string, items *model.Item) error
+	CreateItems(ctx context.Context, orderID string, items []model.Item) error
+	CreateStatusChange(ctx context.Context, orderID string, change *model.StatusChange) error
 
 	GetOrder(ctx context.Context, orderID string) (*model.Order, error)
 	GetDelivery(ctx context.Context, orderID string) (*model.Delivery, error)
 	GetPayment(ctx context.Context, orderID string) (*model.Payment, error)
 	ListItems(ctx context.Context, orderID string) ([]*model.Item, error)
+	GetFullOrder(ctx context.Context, orderID string) (*model.Order, error)
+	GetFullOrders(ctx context.Context, orderIDs []string) ([]*model.Order, error)
+	GetOrderStatus(ctx context.Context, orderID string) (model.OrderStatus, error)
+	GetOrderVersionAt(ctx context.Context, orderID string, t time.Time) (*model.OrderVersion, error)
+	ListOrderVersions(ctx context.Context, orderID string) ([]*model.OrderVersion, error)
+
+	UpdateOrderStatus(ctx context.Context, orderID string, status model.OrderStatus) error
+	UpdateItemStatuses(ctx context.Context, orderID string, from []model.ItemStatus, to model.ItemStatus) error
+	// RecordOrderVersion stores the current state of the order as a version.
+	// Writers call it once after their last write of the order in a
+	// transaction; another call in the same transaction replaces the data of
+	// the version instead of adding one.
+	RecordOrderVersion(ctx context.Context, orderID string) error
 
 	ListOrdersByLastAdded(ctx context.Context, limit int) ([]*model.Order, error)
+	ListMostReadOrders(ctx context.Context, limit int) ([]*model.Order, error)
+	ListOrdersByCustomers(ctx context.Context, customerIDs []string, limit int) ([]*model.Order, error)
+	ListOrderIDs(ctx context.Context, afterID string, limit int) ([]string, error)
+	ListOrders(ctx context.Context, filter *model.OrderFilter, cursor *model.OrderCursor) ([]*model.Order, error)
+	Search(ctx context.Context, query *model.OrderSearchQuery) ([]*model.OrderSearchResult, error)
+
+	RecordReads(ctx context.Context, reads map[string]int, readAt time.Time) error
+}
+
+// PartitionRepository manages the monthly partitions of the order tables.
+type PartitionRepository interface {
+	CreatePartitions(ctx context.Context, from, to time.Time) error
+	ListPartitions(ctx context.Context) ([]time.Time, error)
+	LockPartition(ctx context.Context, month time.Time) error
+	ExportPartition(ctx context.Context, month time.Time, fn func(order *model.Order) error) error
+	DropPartition(ctx context.Context, month time.Time) error
 }
@@ -0,0 +1,65 @@
+package access_log
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	def "github.com/biryanim/wb_tech_L0/internal/service"
+)
+
+var _ def.AccessLogService = (*serv)(nil)
+
+// serv counts order reads in memory and periodically adds them to the
+// persisted counters used by the most-read warm-up strategy.
+type serv struct {
+	orderRepository repository.OrderRepository
+	flushInterval   time.Duration
+
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+func NewService(orderRepository repository.OrderRepository, flushInterval time.Duration) *serv {
+	return &serv{
+		orderRepository: orderRepository,
+		flushInterval:   flushInterval,
+		reads:           make(map[string]int),
+	}
+}
+
+func (s *serv) Record(orderID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reads[orderID]++
+}
+
+func (s *serv) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.flush(context.WithoutCancel(ctx))
+			return ctx.Err()
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+func (s *serv) flush(ctx context.Context) {
+	s.mu.Lock()
+	reads := s.reads
+	s.reads = make(map[string]int)
+	s.mu.Unlock()
+
+	err := s.orderRepository.RecordReads(ctx, reads, time.Now())
+	if err != nil {
+		log.Printf("failed to flush access log: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package cache_admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/cache"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	def "github.com/biryanim/wb_tech_L0/internal/service"
+)
+
+var _ def.CacheAdminService = (*serv)(nil)
+
+type serv struct {
+	cache        cache.Client
+	notFound     cache.Client
+	orderService def.OrderService
+}
+
+func NewService(cache cache.Client, notFound cache.Client, orderService def.OrderService) *serv {
+	return &serv{
+		cache:        cache,
+		notFound:     notFound,
+		orderService: orderService,
+	}
+}
+
+func (s *serv) ListKeys(_ context.Context) []*model.CacheKey {
+	entries := s.cache.Keys()
+
+	keys := make([]*model.CacheKey, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, &model.CacheKey{
+			Key: entry.Key,
+			Age: entry.Age.String(),
+		})
+	}
+
+	return keys
+}
+
+func (s *serv) GetEntry(ctx context.Context, key string) (*model.CacheEntry, error) {
+	cached := s.cache.Peek(key)
+	if cached == nil {
+		return nil, def.ErrNotCached
+	}
+
+	snapshot, ok := cached.(*model.OrderSnapshot)
+	if !ok {
+		return nil, fmt.Errorf("unexpected cached value type %T", cached)
+	}
+
+	order, err := snapshot.Order()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode order snapshot: %w", err)
+	}
+
+	entry := &model.CacheEntry{
+		Key:   key,
+		Value: order,
+	}
+
+	stored, err := s.orderService.LoadOrder(ctx, key)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			entry.DiffersFromDB = true
+			return entry, nil
+		}
+		return nil, err
+	}
+
+	entry.InDB = true
+	entry.DiffersFromDB = !ordersEqual(order, stored)
+
+	return entry, nil
+}
+
+// Evict also forgets that the key was not found, so the next read goes to
+// the DB either way.
+func (s *serv) Evict(_ context.Context, key string) {
+	s.cache.Remove(key)
+	s.notFound.Remove(key)
+}
+
+func (s *serv) Flush(_ context.Context) {
+	s.cache.Flush()
+	s.notFound.Flush()
+}
+
+func (s *serv) Stats(_ context.Context) *model.CacheStats {
+	stats := s.cache.Stats()
+
+	return &model.CacheStats{
+		Hits:      stats.Hits,
+		Misses:    stats.Misses,
+		Evictions: stats.Evictions,
+		Size:      stats.Size,
+	}
+}
+
+// ordersEqual ignores differences that don't change the order itself:
+// the time zone of timestamps and nil versus empty item lists.
+func ordersEqual(a, b *model.Order) bool {
+	if !a.DateCreated.Equal(b.DateCreated) || len(a.Timeline) != len(b.Timeline) {
+		return false
+	}
+	for i := range a.Timeline {
+		l, r := a.Timeline[i], b.Timeline[i]
+		if !l.ChangedAt.Equal(r.ChangedAt) {
+			return false
+		}
+		l.ChangedAt = r.ChangedAt
+		if l != r {
+			return false
+		}
+	}
+
+	left, right := *a, *b
+	left.DateCreated, right.DateCreated = b.DateCreated, b.DateCreated
+	left.Timeline, right.Timeline = nil, nil
+	if len(left.Items) == 0 && len(right.Items) == 0 {
+		left.Items, right.Items = nil, nil
+	}
+
+	return reflect.DeepEqual(left, right)
+}
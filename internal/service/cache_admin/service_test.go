@@ -0,0 +1,174 @@
+package cache_admin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/lru_cache"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/biryanim/wb_tech_L0/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOrderService struct {
+	service.OrderService
+	orders map[string]*model.Order
+}
+
+func (s *fakeOrderService) LoadOrder(ctx context.Context, orderID string) (*model.Order, error) {
+	order, ok := s.orders[orderID]
+	if !ok {
+		return nil, repository.ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+func testOrder(uid string) *model.Order {
+	return &model.Order{
+		OrderUID:    uid,
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		Items:       []model.Item{{ChrtID: 1}},
+	}
+}
+
+func newTestService(stored ...*model.Order) (*serv, *lru_cache.Cache, *lru_cache.Cache) {
+	orders := make(map[string]*model.Order)
+	for _, order := range stored {
+		orders[order.OrderUID] = order
+	}
+
+	c, notFound := lru_cache.New(3), lru_cache.New(3)
+	return NewService(c, notFound, &fakeOrderService{orders: orders}), c, notFound
+}
+
+func cacheOrder(t *testing.T, c *lru_cache.Cache, order *model.Order) {
+	snapshot, err := model.NewOrderSnapshot(order)
+	require.NoError(t, err)
+	c.Set(order.OrderUID, snapshot)
+}
+
+func TestCacheAdmin_GetEntryMatchesDB(t *testing.T) {
+	s, c, _ := newTestService(testOrder("uid1"))
+	cacheOrder(t, c, testOrder("uid1"))
+
+	entry, err := s.GetEntry(context.Background(), "uid1")
+	require.NoError(t, err)
+	assert.Equal(t, "uid1", entry.Value.OrderUID)
+	assert.True(t, entry.InDB)
+	assert.False(t, entry.DiffersFromDB)
+}
+
+func TestCacheAdmin_GetEntryDiffersFromDB(t *testing.T) {
+	stored := testOrder("uid1")
+	stored.TrackNumber = "TRACK2"
+	s, c, _ := newTestService(stored)
+	cacheOrder(t, c, testOrder("uid1"))
+
+	entry, err := s.GetEntry(context.Background(), "uid1")
+	require.NoError(t, err)
+	assert.True(t, entry.InDB)
+	assert.True(t, entry.DiffersFromDB)
+}
+
+func TestCacheAdmin_GetEntryMissingFromDB(t *testing.T) {
+	s, c, _ := newTestService()
+	cacheOrder(t, c, testOrder("uid1"))
+
+	entry, err := s.GetEntry(context.Background(), "uid1")
+	require.NoError(t, err)
+	assert.False(t, entry.InDB)
+	assert.True(t, entry.DiffersFromDB)
+}
+
+func TestCacheAdmin_GetEntryNotCached(t *testing.T) {
+	s, _, _ := newTestService(testOrder("uid1"))
+
+	_, err := s.GetEntry(context.Background(), "uid1")
+	assert.ErrorIs(t, err, service.ErrNotCached)
+}
+
+func TestCacheAdmin_EvictClearsNotFound(t *testing.T) {
+	s, c, notFound := newTestService()
+	cacheOrder(t, c, testOrder("uid1"))
+	notFound.Set("uid1", true)
+	notFound.Set("uid2", true)
+
+	s.Evict(context.Background(), "uid1")
+
+	assert.Nil(t, c.Peek("uid1"))
+	assert.Nil(t, notFound.Peek("uid1"))
+	assert.NotNil(t, notFound.Peek("uid2"))
+}
+
+func TestCacheAdmin_FlushClearsNotFound(t *testing.T) {
+	s, c, notFound := newTestService()
+	cacheOrder(t, c, testOrder("uid1"))
+	notFound.Set("uid2", true)
+
+	s.Flush(context.Background())
+
+	assert.Empty(t, c.Keys())
+	assert.Empty(t, notFound.Keys())
+}
+
+func TestOrdersEqual(t *testing.T) {
+	changedAt := time.Date(2025, time.September, 1, 12, 0, 0, 0, time.UTC)
+	base := func() *model.Order {
+		order := testOrder("uid1")
+		order.Timeline = []model.StatusChange{{To: model.OrderStatusCreated, ChangedAt: changedAt}}
+		return order
+	}
+
+	tests := []struct {
+		name   string
+		change func(order *model.Order)
+		equal  bool
+	}{
+		{
+			name:   "same",
+			change: func(order *model.Order) {},
+			equal:  true,
+		},
+		{
+			name: "other time zone",
+			change: func(order *model.Order) {
+				moscow := time.FixedZone("MSK", 3*60*60)
+				order.DateCreated = order.DateCreated.In(moscow)
+				order.Timeline[0].ChangedAt = changedAt.In(moscow)
+			},
+			equal: true,
+		},
+		{
+			name:   "empty items",
+			change: func(order *model.Order) { order.Items = []model.Item{} },
+			equal:  false,
+		},
+		{
+			name:   "other field",
+			change: func(order *model.Order) { order.CustomerID = "other" },
+			equal:  false,
+		},
+		{
+			name:   "other timeline",
+			change: func(order *model.Order) { order.Timeline[0].ChangedAt = changedAt.Add(time.Second) },
+			equal:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := base()
+			tt.change(order)
+
+			assert.Equal(t, tt.equal, ordersEqual(base(), order))
+		})
+	}
+
+	a, b := base(), base()
+	a.Items, b.Items = nil, []model.Item{}
+	assert.True(t, ordersEqual(a, b))
+}
@@ -0,0 +1,45 @@
+package cache_invalidator
+
+import (
+	"context"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/cache"
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	def "github.com/biryanim/wb_tech_L0/internal/service"
+)
+
+var _ def.CacheInvalidatorService = (*service)(nil)
+
+const orderChangedChannel = "order_changed"
+
+type service struct {
+	listener db.Listener
+	cache    cache.Client
+	notFound cache.Client
+}
+
+func NewService(listener db.Listener, cache cache.Client, notFound cache.Client) *service {
+	return &service{
+		listener: listener,
+		cache:    cache,
+		notFound: notFound,
+	}
+}
+
+func (s *service) RunInvalidator(ctx context.Context) error {
+	return s.listener.Listen(ctx, orderChangedChannel, s.invalidate, s.flush)
+}
+
+func (s *service) invalidate(_ context.Context, orderID string) error {
+	s.cache.Remove(orderID)
+	s.notFound.Remove(orderID)
+
+	return nil
+}
+
+// flush drops everything cached, since changes notified while the listener
+// was reconnecting are lost.
+func (s *service) flush(_ context.Context) {
+	s.cache.Flush()
+	s.notFound.Flush()
+}
@@ -0,0 +1,54 @@
+package cache_invalidator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/lru_cache"
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeListener struct {
+	payloads    []string
+	reconnected bool
+}
+
+func (l *fakeListener) Listen(ctx context.Context, channel string, handler db.NotificationHandler, reconnected db.ReconnectHandler) error {
+	for _, payload := range l.payloads {
+		if err := handler(ctx, payload); err != nil {
+			return err
+		}
+	}
+	if l.reconnected {
+		reconnected(ctx)
+	}
+
+	return nil
+}
+
+func TestInvalidator_RemovesChangedOrders(t *testing.T) {
+	c, notFound := lru_cache.New(3), lru_cache.New(3)
+	c.Set("uid1", 1)
+	c.Set("uid2", 2)
+	notFound.Set("uid3", true)
+	s := NewService(&fakeListener{payloads: []string{"uid1", "uid3"}}, c, notFound)
+
+	assert.NoError(t, s.RunInvalidator(context.Background()))
+
+	assert.Nil(t, c.Get("uid1"))
+	assert.Equal(t, 2, c.Get("uid2"))
+	assert.Nil(t, notFound.Get("uid3"))
+}
+
+func TestInvalidator_FlushesOnReconnect(t *testing.T) {
+	c, notFound := lru_cache.New(3), lru_cache.New(3)
+	c.Set("uid1", 1)
+	notFound.Set("uid2", true)
+	s := NewService(&fakeListener{reconnected: true}, c, notFound)
+
+	assert.NoError(t, s.RunInvalidator(context.Background()))
+
+	assert.Empty(t, c.Keys())
+	assert.Empty(t, notFound.Keys())
+}
@@ -16,14 +16,16 @@ type service struct {
 	consumer        kafka.Consumer
 	txManager       db.TxManager
 	cache           cache.Client
+	notFound        cache.Client
 }
 
-func NewService(orderRepository repository.OrderRepository, consumer kafka.Consumer, txManager db.TxManager, cache cache.Client) *service {
+func NewService(orderRepository repository.OrderRepository, consumer kafka.Consumer, txManager db.TxManager, cache cache.Client, notFound cache.Client) *service {
 	return &service{
 		orderRepository: orderRepository,
 		consumer:        consumer,
 		txManager:       txManager,
 		cache:           cache,
+		notFound:        notFound,
 	}
 }
 
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/IBM/sarama"
+	"github.com/biryanim/wb_tech_L0/internal/client/kafka"
 	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
 )
 
 func (s *service) OrderSaveHandler(ctx context.Context, msg *sarama.ConsumerMessage) error {
@@ -14,7 +16,14 @@ func (s *service) OrderSaveHandler(ctx context.Context, msg *sarama.ConsumerMess
 		return err
 	}
 
+	order.Status = model.OrderStatusCreated
+	order.Timeline = []model.StatusChange{{
+		To:        model.OrderStatusCreated,
+		ChangedAt: order.DateCreated,
+	}}
+
 	//var id uuid.UUID
+	ctx = repository.WithSource(ctx, kafka.Source(msg))
 	err = s.txManager.ReadCommited(ctx, func(ctx context.Context) error {
 		_, err = s.orderRepository.CreateOrder(ctx, order)
 		if err != nil {
@@ -31,21 +40,30 @@ func (s *service) OrderSaveHandler(ctx context.Context, msg *sarama.ConsumerMess
 			return err
 		}
 
-		for _, item := range order.Items {
-			err = s.orderRepository.CreateItem(ctx, order.OrderUID, &item)
-			if err != nil {
-				return err
-			}
+		err = s.orderRepository.CreateItems(ctx, order.OrderUID, order.Items)
+		if err != nil {
+			return err
 		}
 
-		return nil
+		err = s.orderRepository.CreateStatusChange(ctx, order.OrderUID, &order.Timeline[0])
+		if err != nil {
+			return err
+		}
+
+		return s.orderRepository.RecordOrderVersion(ctx, order.OrderUID)
 	})
 
 	if err != nil {
 		return err
 	}
 
-	s.cache.Set(order.OrderUID, order)
+	snapshot, err := model.NewOrderSnapshot(order)
+	if err != nil {
+		return err
+	}
+
+	s.cache.Set(order.OrderUID, snapshot)
+	s.notFound.Remove(order.OrderUID)
 
 	return nil
 }
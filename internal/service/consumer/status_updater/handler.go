@@ -0,0 +1,85 @@
+package status_updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/biryanim/wb_tech_L0/internal/client/kafka"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	"time"
+)
+
+// StatusUpdateHandler applies a status event if the order's state machine
+// allows it, and moves the items that may follow along. Repeated events for
+// the current status are ignored, so redelivered messages are harmless.
+func (s *service) StatusUpdateHandler(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	event := &model.StatusEvent{}
+	err := json.Unmarshal(msg.Value, event)
+	if err != nil {
+		return err
+	}
+	if !event.Status.Valid() {
+		return fmt.Errorf("%w: %q", model.ErrUnknownStatus, event.Status)
+	}
+	if event.ChangedAt.IsZero() {
+		event.ChangedAt = msg.Timestamp
+		if event.ChangedAt.IsZero() {
+			event.ChangedAt = time.Now()
+		}
+	}
+
+	var changed bool
+	ctx = repository.WithSource(ctx, kafka.Source(msg))
+	err = s.txManager.ReadCommited(ctx, func(ctx context.Context) error {
+		current, err := s.orderRepository.GetOrderStatus(ctx, event.OrderUID)
+		if err != nil {
+			return err
+		}
+		if current == event.Status {
+			return nil
+		}
+		if !current.CanTransitionTo(event.Status) {
+			return fmt.Errorf("%w: %s -> %s", model.ErrInvalidTransition, current, event.Status)
+		}
+
+		err = s.orderRepository.UpdateOrderStatus(ctx, event.OrderUID, event.Status)
+		if err != nil {
+			return err
+		}
+
+		itemStatus := event.Status.ItemStatus()
+		err = s.orderRepository.UpdateItemStatuses(ctx, event.OrderUID, model.ItemStatusesBefore(itemStatus), itemStatus)
+		if err != nil {
+			return err
+		}
+
+		err = s.orderRepository.CreateStatusChange(ctx, event.OrderUID, &model.StatusChange{
+			From:      current,
+			To:        event.Status,
+			Reason:    event.Reason,
+			ChangedAt: event.ChangedAt.UTC(),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.orderRepository.RecordOrderVersion(ctx, event.OrderUID)
+		if err != nil {
+			return err
+		}
+
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		s.cache.Remove(event.OrderUID)
+	}
+
+	return nil
+}
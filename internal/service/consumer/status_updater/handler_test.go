@@ -0,0 +1,119 @@
+package status_updater
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/lru_cache"
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTxManager struct {
+	db.TxManager
+}
+
+func (m *fakeTxManager) ReadCommited(ctx context.Context, f db.Handler) error {
+	return f(ctx)
+}
+
+type fakeRepository struct {
+	repository.OrderRepository
+	statuses map[string]model.OrderStatus
+	items    []model.ItemStatus
+	changes  []*model.StatusChange
+	sources  []string
+}
+
+func (r *fakeRepository) GetOrderStatus(ctx context.Context, orderID string) (model.OrderStatus, error) {
+	status, ok := r.statuses[orderID]
+	if !ok {
+		return "", repository.ErrOrderNotFound
+	}
+
+	return status, nil
+}
+
+func (r *fakeRepository) UpdateOrderStatus(ctx context.Context, orderID string, status model.OrderStatus) error {
+	r.statuses[orderID] = status
+	return nil
+}
+
+func (r *fakeRepository) UpdateItemStatuses(ctx context.Context, orderID string, from []model.ItemStatus, to model.ItemStatus) error {
+	for i, status := range r.items {
+		if slices.Contains(from, status) {
+			r.items[i] = to
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) CreateStatusChange(ctx context.Context, orderID string, change *model.StatusChange) error {
+	r.changes = append(r.changes, change)
+	return nil
+}
+
+func (r *fakeRepository) RecordOrderVersion(ctx context.Context, orderID string) error {
+	r.sources = append(r.sources, repository.SourceOf(ctx))
+	return nil
+}
+
+func newTestService() (*service, *fakeRepository, *lru_cache.Cache) {
+	repo := &fakeRepository{
+		statuses: map[string]model.OrderStatus{"uid1": model.OrderStatusCreated},
+		items:    []model.ItemStatus{model.ItemStatusCreated, model.ItemStatusCancelled, 0},
+	}
+	c := lru_cache.New(3)
+
+	return NewService(repo, nil, &fakeTxManager{}, c), repo, c
+}
+
+func message(value string) *sarama.ConsumerMessage {
+	return &sarama.ConsumerMessage{Topic: "order-status-topic", Offset: 42, Value: []byte(value), Timestamp: time.Now()}
+}
+
+func TestStatusUpdateHandler_AppliesTransition(t *testing.T) {
+	s, repo, c := newTestService()
+	c.Set("uid1", "cached")
+
+	err := s.StatusUpdateHandler(context.Background(), message(`{"order_uid":"uid1","status":"paid","reason":"card"}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, model.OrderStatusPaid, repo.statuses["uid1"])
+	assert.Equal(t, []model.ItemStatus{model.ItemStatusPaid, model.ItemStatusCancelled, 0}, repo.items)
+	require.Len(t, repo.changes, 1)
+	assert.Equal(t, model.OrderStatusCreated, repo.changes[0].From)
+	assert.Equal(t, "card", repo.changes[0].Reason)
+	assert.False(t, repo.changes[0].ChangedAt.IsZero())
+	assert.Equal(t, []string{"kafka:order-status-topic/0@42"}, repo.sources)
+	assert.Nil(t, c.Get("uid1"))
+}
+
+func TestStatusUpdateHandler_RejectsInvalidTransition(t *testing.T) {
+	s, repo, _ := newTestService()
+
+	err := s.StatusUpdateHandler(context.Background(), message(`{"order_uid":"uid1","status":"delivered"}`))
+	assert.ErrorIs(t, err, model.ErrInvalidTransition)
+	assert.Equal(t, model.OrderStatusCreated, repo.statuses["uid1"])
+	assert.Empty(t, repo.changes)
+
+	err = s.StatusUpdateHandler(context.Background(), message(`{"order_uid":"uid1","status":"lost"}`))
+	assert.ErrorIs(t, err, model.ErrUnknownStatus)
+}
+
+func TestStatusUpdateHandler_IgnoresRepeatedStatus(t *testing.T) {
+	s, repo, c := newTestService()
+	c.Set("uid1", "cached")
+
+	err := s.StatusUpdateHandler(context.Background(), message(`{"order_uid":"uid1","status":"created"}`))
+	require.NoError(t, err)
+	assert.Empty(t, repo.changes)
+	assert.Empty(t, repo.sources)
+	assert.Equal(t, "cached", c.Get("uid1"))
+}
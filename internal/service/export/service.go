@@ -0,0 +1,62 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/biryanim/wb_tech_L0/internal/config"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	def "github.com/biryanim/wb_tech_L0/internal/service"
+)
+
+var _ def.ExportService = (*serv)(nil)
+
+type serv struct {
+	orderRepository repository.OrderRepository
+	config          config.ExportConfig
+}
+
+func NewService(orderRepository repository.OrderRepository, config config.ExportConfig) *serv {
+	return &serv{
+		orderRepository: orderRepository,
+		config:          config,
+	}
+}
+
+// Export writes every order as newline-delimited JSON. Orders are read in
+// chunks, so memory use does not depend on the size of the table.
+func (s *serv) Export(ctx context.Context, w io.Writer) (int, error) {
+	encoder := json.NewEncoder(w)
+
+	var exported int
+	var afterID string
+	for {
+		ids, err := s.orderRepository.ListOrderIDs(ctx, afterID, s.config.ChunkSize())
+		if err != nil {
+			return exported, fmt.Errorf("failed to list order ids: %w", err)
+		}
+		if len(ids) == 0 {
+			return exported, nil
+		}
+
+		orders, err := s.orderRepository.GetFullOrders(ctx, ids)
+		if err != nil {
+			return exported, fmt.Errorf("failed to get orders: %w", err)
+		}
+
+		for _, order := range orders {
+			err = encoder.Encode(order)
+			if err != nil {
+				return exported, fmt.Errorf("failed to write order: %w", err)
+			}
+			exported++
+		}
+
+		if len(ids) < s.config.ChunkSize() {
+			return exported, nil
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
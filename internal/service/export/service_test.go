@@ -0,0 +1,79 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/biryanim/wb_tech_L0/internal/config"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConfig struct {
+	config.ExportConfig
+}
+
+func (c *fakeConfig) ChunkSize() int {
+	return 2
+}
+
+type fakeRepository struct {
+	repository.OrderRepository
+	ids    []string
+	chunks [][]string
+}
+
+func (r *fakeRepository) ListOrderIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	start := sort.SearchStrings(r.ids, afterID)
+	if start < len(r.ids) && r.ids[start] == afterID {
+		start++
+	}
+
+	return r.ids[start:min(start+limit, len(r.ids))], nil
+}
+
+func (r *fakeRepository) GetFullOrders(ctx context.Context, orderIDs []string) ([]*model.Order, error) {
+	r.chunks = append(r.chunks, orderIDs)
+
+	orders := make([]*model.Order, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		orders = append(orders, &model.Order{OrderUID: orderID})
+	}
+
+	return orders, nil
+}
+
+func TestExport_WritesOrdersInChunks(t *testing.T) {
+	repo := &fakeRepository{ids: []string{"uid1", "uid2", "uid3"}}
+	s := NewService(repo, &fakeConfig{})
+
+	var buf bytes.Buffer
+	exported, err := s.Export(context.Background(), &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 3, exported)
+	assert.Equal(t, [][]string{{"uid1", "uid2"}, {"uid3"}}, repo.chunks)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	var order model.Order
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &order))
+	assert.Equal(t, "uid3", order.OrderUID)
+}
+
+func TestExport_EmptyTable(t *testing.T) {
+	repo := &fakeRepository{}
+	s := NewService(repo, &fakeConfig{})
+
+	var buf bytes.Buffer
+	exported, err := s.Export(context.Background(), &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 0, exported)
+	assert.Empty(t, repo.chunks)
+	assert.Zero(t, buf.Len())
+}
@@ -2,12 +2,28 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
+
 	"github.com/biryanim/wb_tech_L0/internal/client/cache"
 	"github.com/biryanim/wb_tech_L0/internal/client/db"
 	"github.com/biryanim/wb_tech_L0/internal/model"
 	"github.com/biryanim/wb_tech_L0/internal/repository"
 	"github.com/biryanim/wb_tech_L0/internal/service"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	loadTimeout = 5 * time.Second
+
+	defaultListLimit = 50
+	maxListLimit     = 500
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
 )
 
 var _ service.OrderService = (*serv)(nil)
@@ -15,171 +31,250 @@ var _ service.OrderService = (*serv)(nil)
 type serv struct {
 	orderRepository repository.OrderRepository
 	txManager       db.TxManager
-	cache           cache.Client
+	cache           cache.ReadThroughClient
+	notFound        cache.GuardedClient
+	accessLog       service.AccessLogService
+	softTTL         time.Duration
+	hardTTL         time.Duration
+	loads           singleflight.Group
+	refreshes       singleflight.Group
+	now             func() time.Time
 }
 
-func NewService(orderRepository repository.OrderRepository, txManager db.TxManager, cache cache.Client) *serv {
+// NewService creates the order service. Cached orders older than softTTL are
+// served while being refreshed in the background; orders older than hardTTL
+// are reloaded before being served. A zero TTL disables the respective check.
+func NewService(
+	orderRepository repository.OrderRepository,
+	txManager db.TxManager,
+	cache cache.ReadThroughClient,
+	notFound cache.GuardedClient,
+	accessLog service.AccessLogService,
+	softTTL time.Duration,
+	hardTTL time.Duration,
+) *serv {
 	return &serv{
 		orderRepository: orderRepository,
 		txManager:       txManager,
 		cache:           cache,
+		notFound:        notFound,
+		accessLog:       accessLog,
+		softTTL:         softTTL,
+		hardTTL:         hardTTL,
+		now:             time.Now,
 	}
 }
 
+// GetOrder returns a copy of the cached order that the caller may modify.
 func (s *serv) GetOrder(ctx context.Context, orderID string) (*model.Order, error) {
-	if cached := s.cache.Get(orderID); cached != nil {
-		if ord, ok := cached.(*model.Order); ok {
-			return ord, nil
-		}
-		return nil, fmt.Errorf("cache miss")
+	snapshot, _, err := s.GetOrderSnapshot(ctx, orderID)
+	if err != nil {
+		return nil, err
 	}
 
-	orderModel, err := s.orderRepository.GetOrder(ctx, orderID)
+	order, err := snapshot.Order()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get order: %w", err)
+		return nil, fmt.Errorf("failed to decode order snapshot: %w", err)
+	}
+
+	return order, nil
+}
+
+// GetOrderSnapshot lets concurrent requests for the same order share one
+// cache lookup and DB load. The load is detached from the caller's context,
+// so one caller giving up does not fail the others. The returned flag tells
+// whether the snapshot is past its soft expiry and is being refreshed.
+func (s *serv) GetOrderSnapshot(ctx context.Context, orderID string) (*model.OrderSnapshot, bool, error) {
+	resCh := s.loads.DoChan(orderID, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+
+		cached, err := s.cache.GetOrLoad(loadCtx, orderID, s.load)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot, ok := cached.(*model.OrderSnapshot); ok && s.expired(snapshot, s.hardTTL) {
+			return s.reload(loadCtx, orderID)
+		}
+
+		return cached, nil
+	})
+
+	var res singleflight.Result
+	select {
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	case res = <-resCh:
 	}
-	if orderModel == nil {
-		return nil, fmt.Errorf("order not found")
+	if res.Err != nil {
+		return nil, false, res.Err
 	}
 
-	order := &model.Order{
-		OrderUID:          orderModel.OrderUID,
-		TrackNumber:       orderModel.TrackNumber,
-		Entry:             orderModel.Entry,
-		Locale:            orderModel.Locale,
-		InternalSignature: orderModel.InternalSignature,
-		CustomerID:        orderModel.CustomerID,
-		DeliveryService:   orderModel.DeliveryService,
-		ShardKey:          orderModel.ShardKey,
-		SmID:              orderModel.SmID,
-		DateCreated:       orderModel.DateCreated,
-		OofShard:          orderModel.OofShard,
+	snapshot, ok := res.Val.(*model.OrderSnapshot)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected cached value type %T", res.Val)
 	}
+	s.accessLog.Record(orderID)
 
-	deliveryModel, err := s.orderRepository.GetDelivery(ctx, orderID)
+	stale := s.expired(snapshot, s.softTTL)
+	if stale {
+		s.refresh(ctx, orderID)
+	}
+
+	return snapshot, stale, nil
+}
+
+func (s *serv) expired(snapshot *model.OrderSnapshot, ttl time.Duration) bool {
+	return ttl > 0 && s.now().Sub(snapshot.LoadedAt()) >= ttl
+}
+
+// refresh reloads the order in the background. If the DB is unavailable,
+// the cached snapshot keeps being served until its hard expiry.
+func (s *serv) refresh(ctx context.Context, orderID string) {
+	go s.refreshes.Do(orderID, func() (interface{}, error) {
+		refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+
+		_, err := s.reload(refreshCtx, orderID)
+		if err != nil {
+			log.Printf("failed to refresh order %s: %v", orderID, err)
+		}
+
+		return nil, err
+	})
+}
+
+func (s *serv) reload(ctx context.Context, orderID string) (interface{}, error) {
+	snapshot, err := s.load(ctx, orderID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get delivery: %w", err)
-	}
-	if deliveryModel != nil {
-		order.Delivery = model.Delivery{
-			Name:    deliveryModel.Name,
-			Phone:   deliveryModel.Phone,
-			Zip:     deliveryModel.Zip,
-			City:    deliveryModel.City,
-			Address: deliveryModel.Address,
-			Region:  deliveryModel.Region,
-			Email:   deliveryModel.Email,
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			s.cache.Remove(orderID)
 		}
+		return nil, err
 	}
 
-	paymentModel, err := s.orderRepository.GetPayment(ctx, orderID)
+	s.cache.Set(orderID, snapshot)
+
+	return snapshot, nil
+}
+
+// load consults the negative cache before going to the DB, so repeated
+// lookups of unknown orders don't reach Postgres until the entry expires.
+// A miss is not cached if the order was ingested while it was being loaded.
+func (s *serv) load(ctx context.Context, orderID string) (interface{}, error) {
+	gen := s.notFound.Generation(orderID)
+	if s.notFound.Get(orderID) != nil {
+		return nil, fmt.Errorf("failed to get order: %w", repository.ErrOrderNotFound)
+	}
+
+	order, err := s.LoadOrder(ctx, orderID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get payment: %w", err)
-	}
-	if paymentModel != nil {
-		order.Payment = model.Payment{
-			Transaction:  paymentModel.Transaction,
-			RequestID:    paymentModel.RequestID,
-			Currency:     paymentModel.Currency,
-			Provider:     paymentModel.Provider,
-			Amount:       paymentModel.Amount,
-			PaymentDt:    paymentModel.PaymentDt,
-			Bank:         paymentModel.Bank,
-			DeliveryCost: paymentModel.DeliveryCost,
-			GoodsTotal:   paymentModel.GoodsTotal,
-			CustomFee:    paymentModel.CustomFee,
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			s.notFound.SetIfUnchanged(orderID, true, gen)
 		}
+		return nil, err
 	}
 
-	items, err := s.orderRepository.ListItems(ctx, orderID)
+	snapshot, err := model.NewOrderSnapshot(order)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get items: %w", err)
-	}
-	for _, itemModel := range items {
-		order.Items = append(order.Items, model.Item{
-			ChrtID:      itemModel.ChrtID,
-			TrackNumber: itemModel.TrackNumber,
-			Price:       itemModel.Price,
-			Rid:         itemModel.Rid,
-			Name:        itemModel.Name,
-			Size:        itemModel.Size,
-			Sale:        itemModel.Sale,
-			TotalPrice:  itemModel.TotalPrice,
-			NmID:        itemModel.NmID,
-			Brand:       itemModel.Brand,
-			Status:      itemModel.Status,
-		})
-	}
-
-	s.cache.Set(orderID, order)
+		return nil, fmt.Errorf("failed to encode order snapshot: %w", err)
+	}
 
-	return order, nil
+	return snapshot, nil
 }
 
-func (s *serv) RestoreCache(ctx context.Context, limit int) error {
-	orders, err := s.orderRepository.ListOrdersByLastAdded(ctx, limit)
+// LoadOrder reads the order from the DB, bypassing the cache.
+func (s *serv) LoadOrder(ctx context.Context, orderID string) (*model.Order, error) {
+	order, err := s.orderRepository.GetFullOrder(ctx, orderID)
 	if err != nil {
-		return fmt.Errorf("failed to list orders: %w", err)
+		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
 
-	for i := 0; i < len(orders); i++ {
-		delivery, err := s.orderRepository.GetDelivery(ctx, orders[i].OrderUID)
-		if err != nil {
-			return fmt.Errorf("failed to get delivery: %w", err)
-		}
-		if delivery != nil {
-			orders[i].Delivery = model.Delivery{
-				Name:    delivery.Name,
-				Phone:   delivery.Phone,
-				Zip:     delivery.Zip,
-				City:    delivery.City,
-				Address: delivery.Address,
-				Region:  delivery.Region,
-				Email:   delivery.Email,
-			}
-		}
+	return order, nil
+}
 
-		payment, err := s.orderRepository.GetPayment(ctx, orders[i].OrderUID)
+// ListOrders reads a page of orders from the DB. One extra order is fetched
+// to tell whether there is a next page.
+func (s *serv) ListOrders(ctx context.Context, filter *model.OrderFilter, cursor string) (*model.OrderPage, error) {
+	var after *model.OrderCursor
+	if len(cursor) != 0 {
+		var err error
+		after, err = model.DecodeOrderCursor(cursor)
 		if err != nil {
-			return fmt.Errorf("failed to get payment: %w", err)
-		}
-		if payment != nil {
-			orders[i].Payment = model.Payment{
-				Transaction:  payment.Transaction,
-				RequestID:    payment.RequestID,
-				Currency:     payment.Currency,
-				Provider:     payment.Provider,
-				Amount:       payment.Amount,
-				PaymentDt:    payment.PaymentDt,
-				Bank:         payment.Bank,
-				DeliveryCost: payment.DeliveryCost,
-				GoodsTotal:   payment.GoodsTotal,
-				CustomFee:    payment.CustomFee,
-			}
+			return nil, err
 		}
+	}
 
-		items, err := s.orderRepository.ListItems(ctx, orders[i].OrderUID)
-		if err != nil {
-			return fmt.Errorf("failed to get items: %w", err)
-		}
-		for _, itemModel := range items {
-			orders[i].Items = append(orders[i].Items, model.Item{
-				ChrtID:      itemModel.ChrtID,
-				TrackNumber: itemModel.TrackNumber,
-				Price:       itemModel.Price,
-				Rid:         itemModel.Rid,
-				Name:        itemModel.Name,
-				Size:        itemModel.Size,
-				Sale:        itemModel.Sale,
-				TotalPrice:  itemModel.TotalPrice,
-				NmID:        itemModel.NmID,
-				Brand:       itemModel.Brand,
-				Status:      itemModel.Status,
-			})
-		}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	limit = min(limit, maxListLimit)
 
-		s.cache.Set(orders[i].OrderUID, orders[i])
+	query := *filter
+	query.Limit = limit + 1
+	orders, err := s.orderRepository.ListOrders(ctx, &query, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	page := &model.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+		page.NextCursor = (&model.OrderCursor{DateCreated: last.DateCreated, OrderUID: last.OrderUID}).Encode()
+	}
+
+	return page, nil
+}
+
+func (s *serv) Search(ctx context.Context, query *model.OrderSearchQuery) (*model.OrderSearchPage, error) {
+	text := strings.TrimSpace(query.Query)
+	if len(text) == 0 {
+		return nil, model.ErrEmptySearchQuery
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+	offset := max(query.Offset, 0)
+
+	results, err := s.orderRepository.Search(ctx, &model.OrderSearchQuery{
+		Query:  text,
+		Limit:  limit + 1,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search orders: %w", err)
+	}
+
+	page := &model.OrderSearchPage{Results: results}
+	if len(results) > limit {
+		page.Results = results[:limit]
+		next := offset + limit
+		page.NextOffset = &next
+	}
+
+	return page, nil
+}
+
+func (s *serv) ListVersions(ctx context.Context, orderID string) ([]*model.OrderVersion, error) {
+	versions, err := s.orderRepository.ListOrderVersions(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetOrderAsOf returns the order as it was at time t, bypassing the cache.
+func (s *serv) GetOrderAsOf(ctx context.Context, orderID string, t time.Time) (*model.Order, error) {
+	version, err := s.orderRepository.GetOrderVersionAt(ctx, orderID, t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order version: %w", err)
 	}
 
-	return nil
+	return version.Order, nil
 }
@@ -0,0 +1,317 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/guarded_cache"
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/lru_cache"
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/tiered_cache"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/biryanim/wb_tech_L0/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	repository.OrderRepository
+
+	orderCalls atomic.Int32
+	release    chan struct{}
+	missing    atomic.Bool
+	failing    atomic.Bool
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{release: make(chan struct{})}
+}
+
+func (r *fakeRepository) GetFullOrder(ctx context.Context, orderID string) (*model.Order, error) {
+	r.orderCalls.Add(1)
+	select {
+	case <-r.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if r.missing.Load() {
+		return nil, repository.ErrOrderNotFound
+	}
+	if r.failing.Load() {
+		return nil, errors.New("db is down")
+	}
+
+	return &model.Order{OrderUID: orderID}, nil
+}
+
+type fakeAccessLog struct {
+	service.AccessLogService
+}
+
+func (l *fakeAccessLog) Record(orderID string) {}
+
+func newTestService(repo repository.OrderRepository) *serv {
+	return NewService(repo, nil, tiered_cache.New(lru_cache.New(3)), guarded_cache.New(lru_cache.NewWithTTL(3, time.Minute)), &fakeAccessLog{}, 0, 0)
+}
+
+func TestService_GetOrderCoalescesConcurrentMisses(t *testing.T) {
+	repo := newFakeRepository()
+	s := newTestService(repo)
+
+	const callers = 10
+	wg := sync.WaitGroup{}
+	wg.Add(callers)
+	results := make([]*model.Order, callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer wg.Done()
+			order, err := s.GetOrder(context.Background(), "uid1")
+			assert.NoError(t, err)
+			results[i] = order
+		}()
+	}
+
+	assert.Eventually(t, func() bool { return repo.orderCalls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(repo.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), repo.orderCalls.Load())
+	for _, order := range results {
+		require.NotNil(t, order)
+		assert.Equal(t, "uid1", order.OrderUID)
+	}
+}
+
+func TestService_GetOrderCancelledCallerDoesNotFailOthers(t *testing.T) {
+	repo := newFakeRepository()
+	s := newTestService(repo)
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancelledErr := make(chan error, 1)
+	go func() {
+		_, err := s.GetOrder(cancelledCtx, "uid1")
+		cancelledErr <- err
+	}()
+	assert.Eventually(t, func() bool { return repo.orderCalls.Load() == 1 }, time.Second, time.Millisecond)
+
+	waitingResult := make(chan *model.Order, 1)
+	go func() {
+		order, err := s.GetOrder(context.Background(), "uid1")
+		assert.NoError(t, err)
+		waitingResult <- order
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-cancelledErr, context.Canceled)
+
+	close(repo.release)
+	order := <-waitingResult
+	require.NotNil(t, order)
+	assert.Equal(t, "uid1", order.OrderUID)
+	assert.Equal(t, int32(1), repo.orderCalls.Load())
+}
+
+func TestService_GetOrderCachesNotFound(t *testing.T) {
+	repo := newFakeRepository()
+	close(repo.release)
+	repo.missing.Store(true)
+	s := newTestService(repo)
+
+	_, err := s.GetOrder(context.Background(), "uid1")
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+	_, err = s.GetOrder(context.Background(), "uid1")
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+	assert.Equal(t, int32(1), repo.orderCalls.Load())
+
+	repo.missing.Store(false)
+	s.notFound.Remove("uid1")
+
+	order, err := s.GetOrder(context.Background(), "uid1")
+	require.NoError(t, err)
+	assert.Equal(t, "uid1", order.OrderUID)
+	assert.Equal(t, int32(2), repo.orderCalls.Load())
+}
+
+func TestService_GetOrderDoesNotCacheNotFoundIngestedDuringLoad(t *testing.T) {
+	repo := newFakeRepository()
+	repo.missing.Store(true)
+	s := newTestService(repo)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := s.GetOrder(context.Background(), "uid1")
+		errCh <- err
+	}()
+	assert.Eventually(t, func() bool { return repo.orderCalls.Load() == 1 }, time.Second, time.Millisecond)
+
+	// The order is ingested after the DB miss was read but before it is cached.
+	s.notFound.Remove("uid1")
+	close(repo.release)
+	assert.ErrorIs(t, <-errCh, repository.ErrOrderNotFound)
+
+	assert.Nil(t, s.notFound.Get("uid1"))
+}
+
+func TestService_GetOrderSnapshotStaleWhileRevalidate(t *testing.T) {
+	repo := newFakeRepository()
+	close(repo.release)
+	s := NewService(repo, nil, tiered_cache.New(lru_cache.New(3)), guarded_cache.New(lru_cache.New(3)), &fakeAccessLog{}, time.Minute, 10*time.Minute)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	_, stale, err := s.GetOrderSnapshot(context.Background(), "uid1")
+	require.NoError(t, err)
+	assert.False(t, stale)
+
+	now = now.Add(2 * time.Minute)
+	_, stale, err = s.GetOrderSnapshot(context.Background(), "uid1")
+	require.NoError(t, err)
+	assert.True(t, stale)
+	assert.Eventually(t, func() bool { return repo.orderCalls.Load() == 2 }, time.Second, time.Millisecond)
+}
+
+func TestService_GetOrderSnapshotServesStaleOnError(t *testing.T) {
+	repo := newFakeRepository()
+	close(repo.release)
+	s := NewService(repo, nil, tiered_cache.New(lru_cache.New(3)), guarded_cache.New(lru_cache.New(3)), &fakeAccessLog{}, time.Minute, 10*time.Minute)
+	loaded, _, err := s.GetOrderSnapshot(context.Background(), "uid1")
+	require.NoError(t, err)
+
+	repo.failing.Store(true)
+	s.now = func() time.Time { return loaded.LoadedAt().Add(5 * time.Minute) }
+	snapshot, stale, err := s.GetOrderSnapshot(context.Background(), "uid1")
+	require.NoError(t, err)
+	assert.True(t, stale)
+	assert.Equal(t, loaded.ETag(), snapshot.ETag())
+
+	s.now = func() time.Time { return loaded.LoadedAt().Add(11 * time.Minute) }
+	_, _, err = s.GetOrderSnapshot(context.Background(), "uid1")
+	assert.Error(t, err)
+}
+
+type listRepository struct {
+	repository.OrderRepository
+	orders []*model.Order
+}
+
+func (r *listRepository) ListOrders(ctx context.Context, filter *model.OrderFilter, cursor *model.OrderCursor) ([]*model.Order, error) {
+	var orders []*model.Order
+	for _, order := range r.orders {
+		if cursor != nil && !order.DateCreated.Before(cursor.DateCreated) {
+			continue
+		}
+		if len(orders) == filter.Limit {
+			break
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+func TestService_ListOrdersPaginates(t *testing.T) {
+	now := time.Now()
+	repo := &listRepository{}
+	for i := 0; i < 5; i++ {
+		repo.orders = append(repo.orders, &model.Order{
+			OrderUID:    string(rune('a' + i)),
+			DateCreated: now.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	s := newTestService(repo)
+
+	page, err := s.ListOrders(context.Background(), &model.OrderFilter{Limit: 2}, "")
+	require.NoError(t, err)
+	require.Len(t, page.Orders, 2)
+	assert.Equal(t, "b", page.Orders[1].OrderUID)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = s.ListOrders(context.Background(), &model.OrderFilter{Limit: 2}, page.NextCursor)
+	require.NoError(t, err)
+	require.Len(t, page.Orders, 2)
+	assert.Equal(t, "c", page.Orders[0].OrderUID)
+
+	page, err = s.ListOrders(context.Background(), &model.OrderFilter{Limit: 2}, page.NextCursor)
+	require.NoError(t, err)
+	require.Len(t, page.Orders, 1)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = s.ListOrders(context.Background(), &model.OrderFilter{}, "garbage")
+	assert.ErrorIs(t, err, model.ErrInvalidCursor)
+}
+
+type searchRepository struct {
+	repository.OrderRepository
+	query *model.OrderSearchQuery
+}
+
+func (r *searchRepository) Search(ctx context.Context, query *model.OrderSearchQuery) ([]*model.OrderSearchResult, error) {
+	r.query = query
+
+	results := make([]*model.OrderSearchResult, 0, query.Limit)
+	for i := 0; i < query.Limit; i++ {
+		results = append(results, &model.OrderSearchResult{OrderUID: string(rune('a' + query.Offset + i))})
+	}
+
+	return results, nil
+}
+
+func TestService_SearchPaginates(t *testing.T) {
+	repo := &searchRepository{}
+	s := newTestService(repo)
+
+	page, err := s.Search(context.Background(), &model.OrderSearchQuery{Query: " Petrov ", Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "Petrov", repo.query.Query)
+	require.Len(t, page.Results, 2)
+	assert.Equal(t, "c", page.Results[0].OrderUID)
+	require.NotNil(t, page.NextOffset)
+	assert.Equal(t, 4, *page.NextOffset)
+
+	_, err = s.Search(context.Background(), &model.OrderSearchQuery{Query: "  "})
+	assert.ErrorIs(t, err, model.ErrEmptySearchQuery)
+}
+
+type versionRepository struct {
+	repository.OrderRepository
+	versions []*model.OrderVersion
+}
+
+func (r *versionRepository) GetOrderVersionAt(ctx context.Context, orderID string, t time.Time) (*model.OrderVersion, error) {
+	var found *model.OrderVersion
+	for _, version := range r.versions {
+		if !version.CreatedAt.After(t) {
+			found = version
+		}
+	}
+	if found == nil {
+		return nil, repository.ErrOrderNotFound
+	}
+
+	return found, nil
+}
+
+func TestService_GetOrderAsOf(t *testing.T) {
+	created := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	repo := &versionRepository{versions: []*model.OrderVersion{
+		{Version: 1, CreatedAt: created, Order: &model.Order{OrderUID: "uid1", Status: model.OrderStatusCreated}},
+		{Version: 2, CreatedAt: created.Add(time.Hour), Order: &model.Order{OrderUID: "uid1", Status: model.OrderStatusPaid}},
+	}}
+	s := newTestService(repo)
+
+	order, err := s.GetOrderAsOf(context.Background(), "uid1", created.Add(30*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, model.OrderStatusCreated, order.Status)
+
+	order, err = s.GetOrderAsOf(context.Background(), "uid1", created.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, model.OrderStatusPaid, order.Status)
+
+	_, err = s.GetOrderAsOf(context.Background(), "uid1", created.Add(-time.Minute))
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+}
@@ -0,0 +1,191 @@
+package retention
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	"github.com/biryanim/wb_tech_L0/internal/config"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	def "github.com/biryanim/wb_tech_L0/internal/service"
+)
+
+var _ def.RetentionService = (*serv)(nil)
+
+type serv struct {
+	partitionRepository repository.PartitionRepository
+	txManager           db.TxManager
+	config              config.RetentionConfig
+	now                 func() time.Time
+}
+
+func NewService(partitionRepository repository.PartitionRepository, txManager db.TxManager, config config.RetentionConfig) *serv {
+	return &serv{
+		partitionRepository: partitionRepository,
+		txManager:           txManager,
+		config:              config,
+		now:                 time.Now,
+	}
+}
+
+// Run maintains the partitions right away and then once per interval until
+// ctx is done. A failed run is logged and retried on the next tick.
+func (s *serv) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.config.Interval())
+	defer ticker.Stop()
+
+	for {
+		err := s.maintain(ctx)
+		if err != nil {
+			log.Printf("partition maintenance failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *serv) maintain(ctx context.Context) error {
+	now := s.now().UTC()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	err := s.partitionRepository.CreatePartitions(ctx, thisMonth, thisMonth.AddDate(0, s.config.PartitionsAhead(), 0))
+	if err != nil {
+		return err
+	}
+
+	if s.config.MaxAge() == 0 {
+		return nil
+	}
+
+	months, err := s.partitionRepository.ListPartitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	cutoff := now.Add(-s.config.MaxAge())
+	for _, month := range months {
+		// Only whole months older than the cutoff are archived.
+		if month.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+
+		err = s.archive(ctx, month)
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", month.Format("2006-01"), err)
+		}
+	}
+
+	return nil
+}
+
+// archive writes the orders of the month to a gzipped NDJSON file and drops
+// the partition in the same transaction. The file is complete on disk before
+// the drop is committed; if anything fails before the commit, the partition
+// stays and the file is removed. Every run writes a file of its own, so
+// orders that come in for an archived month later never overwrite the
+// earlier archive.
+func (s *serv) archive(ctx context.Context, month time.Time) error {
+	err := os.MkdirAll(s.config.ArchiveDir(), 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create archive dir: %w", err)
+	}
+
+	path := filepath.Join(s.config.ArchiveDir(), fmt.Sprintf("orders_%s_%s.ndjson.gz",
+		month.Format("2006_01"), s.now().UTC().Format("20060102T150405Z")))
+
+	// The transaction may be retried, so every attempt starts from scratch
+	// and removes the file of the attempt before it.
+	var exported int
+	var written, dropped bool
+	err = s.txManager.ReadCommited(ctx, func(ctx context.Context) error {
+		dropped = false
+		if written {
+			err := os.Remove(path)
+			if err != nil {
+				return fmt.Errorf("failed to remove archive: %w", err)
+			}
+			written = false
+		}
+
+		err := s.partitionRepository.LockPartition(ctx, month)
+		if err != nil {
+			return err
+		}
+
+		exported, err = s.writeArchive(ctx, month, path)
+		if err != nil {
+			return err
+		}
+		written = true
+
+		err = s.partitionRepository.DropPartition(ctx, month)
+		if err != nil {
+			return err
+		}
+		dropped = true
+
+		return nil
+	})
+	if err != nil {
+		// A failed commit may still have dropped the partition, so the
+		// archive is kept; at worst a later run archives the orders again.
+		if written && !dropped {
+			if errRemove := os.Remove(path); errRemove != nil {
+				log.Printf("failed to remove archive %s: %v", path, errRemove)
+			}
+		}
+		return err
+	}
+
+	log.Printf("archived %d orders of %s to %s", exported, month.Format("2006-01"), path)
+
+	return nil
+}
+
+// writeArchive exports the month to a temporary file and links it to path,
+// which fails rather than replace a file that already exists.
+func (s *serv) writeArchive(ctx context.Context, month time.Time, path string) (int, error) {
+	file, err := os.CreateTemp(s.config.ArchiveDir(), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	var exported int
+	gz := gzip.NewWriter(file)
+	encoder := json.NewEncoder(gz)
+	err = s.partitionRepository.ExportPartition(ctx, month, func(order *model.Order) error {
+		exported++
+		return encoder.Encode(order)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = gz.Close()
+	if err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	err = file.Sync()
+	if err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	err = os.Link(file.Name(), path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	return exported, nil
+}
@@ -0,0 +1,201 @@
+package retention
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/db"
+	"github.com/biryanim/wb_tech_L0/internal/config"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTxManager runs f once more after each of the first retries failures,
+// like the real manager does on serialization failures.
+type fakeTxManager struct {
+	db.TxManager
+	retries int
+}
+
+func (m *fakeTxManager) ReadCommited(ctx context.Context, f db.Handler) error {
+	for {
+		err := f(ctx)
+		if err == nil || m.retries == 0 {
+			return err
+		}
+		m.retries--
+	}
+}
+
+type fakeConfig struct {
+	config.RetentionConfig
+	dir string
+}
+
+func (c *fakeConfig) MaxAge() time.Duration {
+	return 90 * 24 * time.Hour
+}
+
+func (c *fakeConfig) ArchiveDir() string {
+	return c.dir
+}
+
+func (c *fakeConfig) PartitionsAhead() int {
+	return 2
+}
+
+type fakeRepository struct {
+	repository.PartitionRepository
+	months    []time.Time
+	orderIDs  []string
+	created   [][2]time.Time
+	dropped   []time.Time
+	exportErr error
+	dropErrs  []error
+}
+
+func (r *fakeRepository) CreatePartitions(ctx context.Context, from, to time.Time) error {
+	r.created = append(r.created, [2]time.Time{from, to})
+	return nil
+}
+
+func (r *fakeRepository) ListPartitions(ctx context.Context) ([]time.Time, error) {
+	return r.months, nil
+}
+
+func (r *fakeRepository) LockPartition(ctx context.Context, month time.Time) error {
+	return nil
+}
+
+func (r *fakeRepository) ExportPartition(ctx context.Context, month time.Time, fn func(*model.Order) error) error {
+	if r.exportErr != nil {
+		return r.exportErr
+	}
+
+	orderIDs := r.orderIDs
+	if orderIDs == nil {
+		orderIDs = []string{"uid1", "uid2"}
+	}
+	for _, orderID := range orderIDs {
+		err := fn(&model.Order{OrderUID: orderID, DateCreated: month})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeRepository) DropPartition(ctx context.Context, month time.Time) error {
+	if len(r.dropErrs) != 0 {
+		err := r.dropErrs[0]
+		r.dropErrs = r.dropErrs[1:]
+		return err
+	}
+
+	r.dropped = append(r.dropped, month)
+	return nil
+}
+
+func month(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func newTestService(t *testing.T, repo *fakeRepository) (*serv, string) {
+	dir := t.TempDir()
+	s := NewService(repo, &fakeTxManager{}, &fakeConfig{dir: dir})
+	s.now = func() time.Time { return time.Date(2025, time.October, 15, 12, 0, 0, 0, time.UTC) }
+
+	return s, dir
+}
+
+func readArchive(t *testing.T, path string) []string {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	var orderIDs []string
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var order model.Order
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &order))
+		orderIDs = append(orderIDs, order.OrderUID)
+	}
+	require.NoError(t, scanner.Err())
+
+	return orderIDs
+}
+
+func TestRetention_ArchivesOldPartitions(t *testing.T) {
+	repo := &fakeRepository{months: []time.Time{month(2025, time.May), month(2025, time.June), month(2025, time.July)}}
+	s, dir := newTestService(t, repo)
+
+	require.NoError(t, s.maintain(context.Background()))
+
+	assert.Equal(t, [][2]time.Time{{month(2025, time.October), month(2025, time.December)}}, repo.created)
+	assert.Equal(t, []time.Time{month(2025, time.May), month(2025, time.June)}, repo.dropped)
+
+	assert.Equal(t, []string{"uid1", "uid2"}, readArchive(t, filepath.Join(dir, "orders_2025_05_20251015T120000Z.ndjson.gz")))
+}
+
+func TestRetention_ArchivingMonthAgainKeepsEarlierArchive(t *testing.T) {
+	repo := &fakeRepository{months: []time.Time{month(2025, time.May)}}
+	s, dir := newTestService(t, repo)
+	require.NoError(t, s.maintain(context.Background()))
+
+	// A late order got the month a new partition.
+	repo.orderIDs = []string{"uid3"}
+	s.now = func() time.Time { return time.Date(2025, time.October, 16, 12, 0, 0, 0, time.UTC) }
+	require.NoError(t, s.maintain(context.Background()))
+
+	assert.Equal(t, []string{"uid1", "uid2"}, readArchive(t, filepath.Join(dir, "orders_2025_05_20251015T120000Z.ndjson.gz")))
+	assert.Equal(t, []string{"uid3"}, readArchive(t, filepath.Join(dir, "orders_2025_05_20251016T120000Z.ndjson.gz")))
+}
+
+func TestRetention_RetriedArchiveWritesOrdersOnce(t *testing.T) {
+	repo := &fakeRepository{months: []time.Time{month(2025, time.May)}, dropErrs: []error{errors.New("deadlock detected")}}
+	s, dir := newTestService(t, repo)
+	s.txManager = &fakeTxManager{retries: 1}
+
+	require.NoError(t, s.maintain(context.Background()))
+	assert.Equal(t, []time.Time{month(2025, time.May)}, repo.dropped)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{"uid1", "uid2"}, readArchive(t, filepath.Join(dir, entries[0].Name())))
+}
+
+func TestRetention_FailedDropRemovesArchive(t *testing.T) {
+	repo := &fakeRepository{months: []time.Time{month(2025, time.May)}, dropErrs: []error{errors.New("lock timeout")}}
+	s, dir := newTestService(t, repo)
+
+	require.Error(t, s.maintain(context.Background()))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRetention_FailedExportKeepsPartition(t *testing.T) {
+	repo := &fakeRepository{months: []time.Time{month(2025, time.May)}, exportErr: errors.New("connection reset")}
+	s, dir := newTestService(t, repo)
+
+	require.Error(t, s.maintain(context.Background()))
+	assert.Empty(t, repo.dropped)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
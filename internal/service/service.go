@@ -2,14 +2,56 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/errs"
 	"github.com/biryanim/wb_tech_L0/internal/model"
 )
 
+var ErrNotCached = errs.NotFound("key is not cached")
+
 type ConsumerService interface {
 	RunConsumer(ctx context.Context) error
 }
 
+type CacheInvalidatorService interface {
+	RunInvalidator(ctx context.Context) error
+}
+
 type OrderService interface {
 	GetOrder(ctx context.Context, orderID string) (*model.Order, error)
-	RestoreCache(ctx context.Context, limit int) error
+	GetOrderSnapshot(ctx context.Context, orderID string) (*model.OrderSnapshot, bool, error)
+	LoadOrder(ctx context.Context, orderID string) (*model.Order, error)
+	ListOrders(ctx context.Context, filter *model.OrderFilter, cursor string) (*model.OrderPage, error)
+	Search(ctx context.Context, query *model.OrderSearchQuery) (*model.OrderSearchPage, error)
+	ListVersions(ctx context.Context, orderID string) ([]*model.OrderVersion, error)
+	GetOrderAsOf(ctx context.Context, orderID string, t time.Time) (*model.Order, error)
+}
+
+type AccessLogService interface {
+	Record(orderID string)
+	Run(ctx context.Context) error
+}
+
+type WarmUpService interface {
+	Run(ctx context.Context) error
+	Skip(ctx context.Context)
+	Progress(ctx context.Context) *model.WarmUpProgress
+}
+
+type CacheAdminService interface {
+	ListKeys(ctx context.Context) []*model.CacheKey
+	GetEntry(ctx context.Context, key string) (*model.CacheEntry, error)
+	Evict(ctx context.Context, key string)
+	Flush(ctx context.Context)
+	Stats(ctx context.Context) *model.CacheStats
+}
+
+type ExportService interface {
+	Export(ctx context.Context, w io.Writer) (int, error)
+}
+
+type RetentionService interface {
+	Run(ctx context.Context) error
 }
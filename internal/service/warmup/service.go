@@ -0,0 +1,142 @@
+package warmup
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/cache"
+	"github.com/biryanim/wb_tech_L0/internal/config"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	def "github.com/biryanim/wb_tech_L0/internal/service"
+)
+
+var _ def.WarmUpService = (*serv)(nil)
+
+type serv struct {
+	orderRepository repository.OrderRepository
+	cache           cache.GuardedClient
+	config          config.WarmUpConfig
+
+	mu       sync.RWMutex
+	progress model.WarmUpProgress
+}
+
+func NewService(orderRepository repository.OrderRepository, cache cache.GuardedClient, config config.WarmUpConfig) *serv {
+	return &serv{
+		orderRepository: orderRepository,
+		cache:           cache,
+		config:          config,
+		progress: model.WarmUpProgress{
+			Strategy: config.Strategy(),
+			State:    model.WarmUpStatePending,
+		},
+	}
+}
+
+func (s *serv) Run(ctx context.Context) error {
+	s.update(func(p *model.WarmUpProgress) {
+		now := time.Now()
+		p.State = model.WarmUpStateRunning
+		p.StartedAt = &now
+	})
+
+	err := s.run(ctx)
+
+	s.update(func(p *model.WarmUpProgress) {
+		now := time.Now()
+		p.FinishedAt = &now
+		p.State = model.WarmUpStateDone
+		if err != nil {
+			p.State = model.WarmUpStateFailed
+			p.Error = err.Error()
+		}
+	})
+
+	return err
+}
+
+func (s *serv) Skip(_ context.Context) {
+	s.update(func(p *model.WarmUpProgress) {
+		p.State = model.WarmUpStateSkipped
+	})
+}
+
+func (s *serv) Progress(_ context.Context) *model.WarmUpProgress {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	progress := s.progress
+	return &progress
+}
+
+func (s *serv) run(ctx context.Context) error {
+	orders, err := s.listOrders(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	s.update(func(p *model.WarmUpProgress) {
+		p.Total = len(orders)
+	})
+	log.Printf("cache warm-up: loading %d orders using %s strategy", len(orders), s.config.Strategy())
+
+	loaded := 0
+	for start := 0; start < len(orders); start += s.config.ChunkSize() {
+		end := min(start+s.config.ChunkSize(), len(orders))
+		ids := make([]string, 0, end-start)
+		// An order changed or invalidated while the chunk loads is not
+		// overwritten with what the chunk read before.
+		generations := make(map[string]uint64, end-start)
+		for _, order := range orders[start:end] {
+			ids = append(ids, order.OrderUID)
+			generations[order.OrderUID] = s.cache.Generation(order.OrderUID)
+		}
+
+		chunk, err := s.orderRepository.GetFullOrders(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to get orders: %w", err)
+		}
+
+		for _, order := range chunk {
+			snapshot, err := model.NewOrderSnapshot(order)
+			if err != nil {
+				return fmt.Errorf("failed to encode order snapshot: %w", err)
+			}
+			s.cache.SetIfUnchanged(order.OrderUID, snapshot, generations[order.OrderUID])
+		}
+
+		loaded += len(chunk)
+		s.update(func(p *model.WarmUpProgress) {
+			p.Loaded = loaded
+		})
+		log.Printf("cache warm-up: loaded %d/%d orders", loaded, len(orders))
+	}
+
+	log.Printf("cache warm-up: done, loaded %d orders", loaded)
+
+	return nil
+}
+
+func (s *serv) listOrders(ctx context.Context) ([]*model.Order, error) {
+	switch s.config.Strategy() {
+	case config.WarmUpStrategyRecent:
+		return s.orderRepository.ListOrdersByLastAdded(ctx, s.config.Limit())
+	case config.WarmUpStrategyMostRead:
+		return s.orderRepository.ListMostReadOrders(ctx, s.config.Limit())
+	case config.WarmUpStrategyCustomers:
+		return s.orderRepository.ListOrdersByCustomers(ctx, s.config.CustomerIDs(), s.config.Limit())
+	default:
+		return nil, nil
+	}
+}
+
+func (s *serv) update(f func(p *model.WarmUpProgress)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f(&s.progress)
+}
@@ -0,0 +1,109 @@
+package warmup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/guarded_cache"
+	"github.com/biryanim/wb_tech_L0/internal/client/cache/lru_cache"
+	"github.com/biryanim/wb_tech_L0/internal/config"
+	"github.com/biryanim/wb_tech_L0/internal/model"
+	"github.com/biryanim/wb_tech_L0/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConfig struct {
+	config.WarmUpConfig
+	strategy string
+}
+
+func (c *fakeConfig) Strategy() string {
+	return c.strategy
+}
+
+func (c *fakeConfig) Limit() int {
+	return 10
+}
+
+func (c *fakeConfig) ChunkSize() int {
+	return 2
+}
+
+type fakeRepository struct {
+	repository.OrderRepository
+	chunks [][]string
+	onLoad func()
+}
+
+func (r *fakeRepository) ListMostReadOrders(ctx context.Context, limit int) ([]*model.Order, error) {
+	return []*model.Order{{OrderUID: "uid1"}, {OrderUID: "uid2"}, {OrderUID: "uid3"}}, nil
+}
+
+func (r *fakeRepository) GetFullOrders(ctx context.Context, orderIDs []string) ([]*model.Order, error) {
+	r.chunks = append(r.chunks, orderIDs)
+	if r.onLoad != nil {
+		r.onLoad()
+	}
+
+	orders := make([]*model.Order, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		orders = append(orders, &model.Order{
+			OrderUID: orderID,
+			Delivery: model.Delivery{Name: "Ivan Petrov"},
+			Payment:  model.Payment{Transaction: orderID},
+			Items:    []model.Item{{ChrtID: 1}},
+		})
+	}
+
+	return orders, nil
+}
+
+func TestWarmUp_RunLoadsOrdersIntoCache(t *testing.T) {
+	c := guarded_cache.New(lru_cache.New(3))
+	repo := &fakeRepository{}
+	s := NewService(repo, c, &fakeConfig{strategy: config.WarmUpStrategyMostRead})
+
+	require.NoError(t, s.Run(context.Background()))
+	assert.Equal(t, [][]string{{"uid1", "uid2"}, {"uid3"}}, repo.chunks)
+
+	snapshot, ok := c.Get("uid2").(*model.OrderSnapshot)
+	require.True(t, ok)
+	cached, err := snapshot.Order()
+	require.NoError(t, err)
+	assert.Equal(t, "Ivan Petrov", cached.Delivery.Name)
+	assert.Equal(t, "uid2", cached.Payment.Transaction)
+	assert.Len(t, cached.Items, 1)
+
+	progress := s.Progress(context.Background())
+	assert.Equal(t, model.WarmUpStateDone, progress.State)
+	assert.Equal(t, 3, progress.Total)
+	assert.Equal(t, 3, progress.Loaded)
+	assert.NotNil(t, progress.FinishedAt)
+}
+
+func TestWarmUp_RunDoesNotOverwriteOrdersChangedDuringLoad(t *testing.T) {
+	c := guarded_cache.New(lru_cache.New(3))
+	repo := &fakeRepository{}
+	repo.onLoad = func() {
+		c.Remove("uid1")
+		c.Set("uid2", "fresh")
+		repo.onLoad = nil
+	}
+	s := NewService(repo, c, &fakeConfig{strategy: config.WarmUpStrategyMostRead})
+
+	require.NoError(t, s.Run(context.Background()))
+	assert.Nil(t, c.Get("uid1"))
+	assert.Equal(t, "fresh", c.Get("uid2"))
+	assert.NotNil(t, c.Get("uid3"))
+}
+
+func TestWarmUp_RunNoneStrategy(t *testing.T) {
+	c := guarded_cache.New(lru_cache.New(3))
+	s := NewService(&fakeRepository{}, c, &fakeConfig{strategy: config.WarmUpStrategyNone})
+
+	require.NoError(t, s.Run(context.Background()))
+
+	assert.Equal(t, 0, c.Stats().Size)
+	assert.Equal(t, model.WarmUpStateDone, s.Progress(context.Background()).State)
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+create table order_reads (
+    order_uid varchar(255) primary key,
+    read_count bigint not null,
+    last_read_at timestamp not null,
+    foreign key (order_uid) references orders(order_uid) on delete cascade
+);
+
+create index idx_order_reads_read_count on order_reads(read_count desc, last_read_at desc);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index idx_order_reads_read_count;
+
+drop table order_reads;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+create function notify_order_changed() returns trigger as $$
+begin
+    if tg_op = 'DELETE' then
+        perform pg_notify('order_changed', old.order_uid);
+    else
+        perform pg_notify('order_changed', new.order_uid);
+    end if;
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger orders_notify_changed after insert or update or delete on orders
+    for each row execute function notify_order_changed();
+create trigger deliveries_notify_changed after insert or update or delete on deliveries
+    for each row execute function notify_order_changed();
+create trigger payments_notify_changed after insert or update or delete on payments
+    for each row execute function notify_order_changed();
+create trigger items_notify_changed after insert or update or delete on items
+    for each row execute function notify_order_changed();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop trigger items_notify_changed on items;
+drop trigger payments_notify_changed on payments;
+drop trigger deliveries_notify_changed on deliveries;
+drop trigger orders_notify_changed on orders;
+
+drop function notify_order_changed();
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+create index idx_orders_date_created_order_uid on orders(date_created desc, order_uid desc);
+create index idx_orders_track_number on orders(track_number);
+create index idx_items_brand on items(brand);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index idx_items_brand;
+drop index idx_orders_track_number;
+drop index idx_orders_date_created_order_uid;
+-- +goose StatementEnd
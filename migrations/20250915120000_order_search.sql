@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+create extension if not exists pg_trgm;
+
+create table order_search(
+    order_uid varchar(255) primary key,
+    document text not null,
+    search_vector tsvector generated always as (to_tsvector('simple', document)) stored,
+    foreign key (order_uid) references orders(order_uid) on delete cascade
+);
+
+create index idx_order_search_vector on order_search using gin(search_vector);
+create index idx_order_search_document_trgm on order_search using gin(document gin_trgm_ops);
+
+create function refresh_order_search(uid varchar) returns void as $$
+begin
+    insert into order_search(order_uid, document)
+    select o.order_uid,
+           concat_ws(' ',
+               o.track_number,
+               d.name, d.city, d.address, d.region, d.email,
+               (select string_agg(concat_ws(' ', i.name, i.brand), ' ' order by i.id)
+                from items i
+                where i.order_uid = o.order_uid))
+    from orders o
+    left join deliveries d on d.order_uid = o.order_uid
+    where o.order_uid = uid
+    on conflict (order_uid) do update set document = excluded.document;
+end;
+$$ language plpgsql;
+
+create function order_search_changed() returns trigger as $$
+begin
+    if tg_op = 'DELETE' then
+        perform refresh_order_search(old.order_uid);
+    else
+        perform refresh_order_search(new.order_uid);
+    end if;
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger orders_search_changed after insert or update on orders
+    for each row execute function order_search_changed();
+create trigger deliveries_search_changed after insert or update or delete on deliveries
+    for each row execute function order_search_changed();
+create trigger items_search_changed after insert or update or delete on items
+    for each row execute function order_search_changed();
+
+select refresh_order_search(order_uid) from orders;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop trigger items_search_changed on items;
+drop trigger deliveries_search_changed on deliveries;
+drop trigger orders_search_changed on orders;
+
+drop function order_search_changed();
+drop function refresh_order_search(varchar);
+
+drop table order_search;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table orders add column status varchar(32) not null default 'created';
+
+create table order_status_history(
+    id bigint generated always as identity primary key,
+    order_uid varchar(255) not null,
+    from_status varchar(32),
+    to_status varchar(32) not null,
+    reason text not null default '',
+    changed_at timestamp not null,
+    foreign key (order_uid) references orders(order_uid) on delete cascade
+);
+
+create index idx_order_status_history_order_id on order_status_history(order_uid, changed_at);
+
+insert into order_status_history(order_uid, to_status, changed_at)
+select order_uid, status, date_created from orders;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index idx_order_status_history_order_id;
+drop table order_status_history;
+
+alter table orders drop column status;
+-- +goose StatementEnd
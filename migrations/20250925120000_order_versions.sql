@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+create table order_versions(
+    id bigint generated always as identity primary key,
+    order_uid varchar(255) not null,
+    version int not null,
+    source text not null,
+    created_at timestamp not null,
+    data jsonb not null,
+    unique (order_uid, version)
+);
+
+create index idx_order_versions_created_at on order_versions(order_uid, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index idx_order_versions_created_at;
+drop table order_versions;
+-- +goose StatementEnd
@@ -0,0 +1,291 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- orders, deliveries, payments and items are partitioned by month of the
+-- order's date_created. Child tables carry date_created too, so the rows of
+-- one month live in partitions with the same bounds and are dropped together
+-- by the retention job. Partitioned tables can only enforce uniqueness and
+-- foreign keys that include the partition key, so the tables no longer
+-- reference orders; the retention job cleans up the dependent tables.
+alter table items drop constraint items_order_uid_fkey;
+alter table order_reads drop constraint order_reads_order_uid_fkey;
+alter table order_search drop constraint order_search_order_uid_fkey;
+alter table order_status_history drop constraint order_status_history_order_uid_fkey;
+
+alter table orders rename to orders_unpartitioned;
+alter table deliveries rename to deliveries_unpartitioned;
+alter table payments rename to payments_unpartitioned;
+alter table items rename to items_unpartitioned;
+
+create table orders (
+    order_uid varchar(255) not null,
+    track_number varchar(255) not null,
+    entry varchar(255) not null,
+    locale varchar(10) not null,
+    internal_signature varchar(255),
+    customer_id varchar(255) not null,
+    delivery_service varchar(255) not null,
+    shardKey varchar(255) not null,
+    sm_id int not null,
+    date_created timestamp not null,
+    oof_shard varchar(255) not null,
+    status varchar(32) not null default 'created',
+    primary key (order_uid, date_created)
+) partition by range (date_created);
+
+create table deliveries(
+    order_uid varchar(255) not null,
+    date_created timestamp not null,
+    name varchar(255) not null,
+    phone varchar(50) not null,
+    zip varchar(20) not null,
+    city varchar(255) not null,
+    address text not null,
+    region varchar(255) not null,
+    email varchar(255) not null,
+    primary key (order_uid, date_created)
+) partition by range (date_created);
+
+create table payments(
+    order_uid varchar(255) not null,
+    date_created timestamp not null,
+    transaction varchar not null,
+    request_id varchar(255),
+    currency varchar(10) not null,
+    provider varchar(255) not null,
+    amount decimal not null,
+    payment_dt int not null,
+    bank varchar(255) not null,
+    delivery_cost decimal not null,
+    goods_total int not null,
+    custom_fee decimal not null,
+    primary key (order_uid, date_created)
+) partition by range (date_created);
+
+create table items(
+    id int generated always as identity,
+    order_uid varchar(255) not null,
+    date_created timestamp not null,
+    chrt_id int not null,
+    track_number varchar(255) not null,
+    price decimal not null,
+    rid varchar(255) not null,
+    name varchar(255) not null,
+    sale int not null,
+    size varchar(50) not null,
+    total_price decimal not null,
+    nm_id int not null,
+    brand varchar(255) not null,
+    status int not null,
+    primary key (id, date_created)
+) partition by range (date_created);
+
+create table orders_default partition of orders default;
+create table deliveries_default partition of deliveries default;
+create table payments_default partition of payments default;
+create table items_default partition of items default;
+
+-- create_order_partitions creates the monthly partitions of all order tables
+-- for every month from from_month to to_month inclusive.
+create function create_order_partitions(from_month timestamp, to_month timestamp) returns void as $$
+declare
+    month timestamp := date_trunc('month', from_month);
+    tbl text;
+begin
+    while month <= to_month loop
+        foreach tbl in array array['orders', 'deliveries', 'payments', 'items'] loop
+            execute format(
+                'create table if not exists %I partition of %I for values from (%L) to (%L)',
+                tbl || to_char(month, '"_p"YYYY_MM'), tbl, month, month + interval '1 month'
+            );
+        end loop;
+        month := month + interval '1 month';
+    end loop;
+end;
+$$ language plpgsql;
+
+select create_order_partitions(
+    coalesce((select min(date_created) from orders_unpartitioned), now()::timestamp),
+    (now() + interval '3 months')::timestamp
+);
+
+insert into orders
+select order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service,
+       shardKey, sm_id, date_created, oof_shard, status
+from orders_unpartitioned;
+
+insert into deliveries
+select d.order_uid, o.date_created, d.name, d.phone, d.zip, d.city, d.address, d.region, d.email
+from deliveries_unpartitioned d
+join orders_unpartitioned o on o.order_uid = d.order_uid;
+
+insert into payments
+select p.order_uid, o.date_created, p.transaction, p.request_id, p.currency, p.provider, p.amount,
+       p.payment_dt, p.bank, p.delivery_cost, p.goods_total, p.custom_fee
+from payments_unpartitioned p
+join orders_unpartitioned o on o.order_uid = p.order_uid;
+
+insert into items overriding system value
+select i.id, i.order_uid, o.date_created, i.chrt_id, i.track_number, i.price, i.rid, i.name, i.sale,
+       i.size, i.total_price, i.nm_id, i.brand, i.status
+from items_unpartitioned i
+join orders_unpartitioned o on o.order_uid = i.order_uid;
+
+select setval(pg_get_serial_sequence('items', 'id'), coalesce(max(id), 0) + 1, false) from items;
+
+drop table items_unpartitioned;
+drop table payments_unpartitioned;
+drop table deliveries_unpartitioned;
+drop table orders_unpartitioned;
+
+create index idx_orders_date_created on orders(date_created);
+create index idx_orders_customer_id on orders(customer_id);
+create index idx_orders_date_created_order_uid on orders(date_created desc, order_uid desc);
+create index idx_orders_track_number on orders(track_number);
+create index idx_orders_order_uid on orders(order_uid);
+create index idx_deliveries_order_id on deliveries(order_uid);
+create index idx_payments_order_id on payments(order_uid);
+create index idx_items_order_id on items(order_uid);
+create index idx_items_brand on items(brand);
+
+create trigger orders_notify_changed after insert or update or delete on orders
+    for each row execute function notify_order_changed();
+create trigger deliveries_notify_changed after insert or update or delete on deliveries
+    for each row execute function notify_order_changed();
+create trigger payments_notify_changed after insert or update or delete on payments
+    for each row execute function notify_order_changed();
+create trigger items_notify_changed after insert or update or delete on items
+    for each row execute function notify_order_changed();
+
+create trigger orders_search_changed after insert or update on orders
+    for each row execute function order_search_changed();
+create trigger deliveries_search_changed after insert or update or delete on deliveries
+    for each row execute function order_search_changed();
+create trigger items_search_changed after insert or update or delete on items
+    for each row execute function order_search_changed();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table orders rename to orders_partitioned;
+alter table deliveries rename to deliveries_partitioned;
+alter table payments rename to payments_partitioned;
+alter table items rename to items_partitioned;
+
+create table orders (
+    order_uid varchar(255) primary key,
+    track_number varchar(255) not null,
+    entry varchar(255) not null,
+    locale varchar(10) not null,
+    internal_signature varchar(255),
+    customer_id varchar(255) not null,
+    delivery_service varchar(255) not null,
+    shardKey varchar(255) not null,
+    sm_id int not null,
+    date_created timestamp not null,
+    oof_shard varchar(255) not null,
+    status varchar(32) not null default 'created'
+);
+
+create table deliveries(
+    order_uid varchar(255) primary key,
+    name varchar(255) not null,
+    phone varchar(50) not null,
+    zip varchar(20) not null,
+    city varchar(255) not null,
+    address text not null,
+    region varchar(255) not null,
+    email varchar(255) not null
+);
+
+create table payments(
+    order_uid varchar(255) primary key,
+    transaction varchar not null,
+    request_id varchar(255),
+    currency varchar(10) not null,
+    provider varchar(255) not null,
+    amount decimal not null,
+    payment_dt int not null,
+    bank varchar(255) not null,
+    delivery_cost decimal not null,
+    goods_total int not null,
+    custom_fee decimal not null
+);
+
+create table items(
+    id int generated always as identity primary key,
+    order_uid varchar(255) not null,
+    chrt_id int not null,
+    track_number varchar(255) not null,
+    price decimal not null,
+    rid varchar(255) not null,
+    name varchar(255) not null,
+    sale int not null,
+    size varchar(50) not null,
+    total_price decimal not null,
+    nm_id int not null,
+    brand varchar(255) not null,
+    status int not null,
+    foreign key (order_uid) references orders(order_uid) on delete cascade
+);
+
+insert into orders
+select order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service,
+       shardKey, sm_id, date_created, oof_shard, status
+from orders_partitioned;
+
+insert into deliveries
+select order_uid, name, phone, zip, city, address, region, email
+from deliveries_partitioned;
+
+insert into payments
+select order_uid, transaction, request_id, currency, provider, amount, payment_dt, bank,
+       delivery_cost, goods_total, custom_fee
+from payments_partitioned;
+
+insert into items overriding system value
+select id, order_uid, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status
+from items_partitioned;
+
+select setval(pg_get_serial_sequence('items', 'id'), coalesce(max(id), 0) + 1, false) from items;
+
+drop table items_partitioned;
+drop table payments_partitioned;
+drop table deliveries_partitioned;
+drop table orders_partitioned;
+
+drop function create_order_partitions(timestamp, timestamp);
+
+delete from order_reads where order_uid not in (select order_uid from orders);
+delete from order_search where order_uid not in (select order_uid from orders);
+delete from order_status_history where order_uid not in (select order_uid from orders);
+
+alter table order_reads add foreign key (order_uid) references orders(order_uid) on delete cascade;
+alter table order_search add foreign key (order_uid) references orders(order_uid) on delete cascade;
+alter table order_status_history add foreign key (order_uid) references orders(order_uid) on delete cascade;
+
+create index idx_orders_date_created on orders(date_created);
+create index idx_orders_customer_id on orders(customer_id);
+create index idx_orders_date_created_order_uid on orders(date_created desc, order_uid desc);
+create index idx_orders_track_number on orders(track_number);
+create index idx_deliveries_order_id on deliveries(order_uid);
+create index idx_payments_order_id on payments(order_uid);
+create index idx_items_order_id on items(order_uid);
+create index idx_items_brand on items(brand);
+
+create trigger orders_notify_changed after insert or update or delete on orders
+    for each row execute function notify_order_changed();
+create trigger deliveries_notify_changed after insert or update or delete on deliveries
+    for each row execute function notify_order_changed();
+create trigger payments_notify_changed after insert or update or delete on payments
+    for each row execute function notify_order_changed();
+create trigger items_notify_changed after insert or update or delete on items
+    for each row execute function notify_order_changed();
+
+create trigger orders_search_changed after insert or update on orders
+    for each row execute function order_search_changed();
+create trigger deliveries_search_changed after insert or update or delete on deliveries
+    for each row execute function order_search_changed();
+create trigger items_search_changed after insert or update or delete on items
+    for each row execute function order_search_changed();
+-- +goose StatementEnd
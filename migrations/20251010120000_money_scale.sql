@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Amounts are kept in kopecks. Casting to numeric(14, 2) rounds the amounts
+-- written with more decimal places before.
+alter table payments
+    alter column amount type numeric(14, 2),
+    alter column delivery_cost type numeric(14, 2),
+    alter column custom_fee type numeric(14, 2);
+
+alter table items
+    alter column price type numeric(14, 2),
+    alter column total_price type numeric(14, 2);
+
+update order_versions v
+set data = jsonb_set(
+        jsonb_set(
+            jsonb_set(
+                jsonb_set(v.data, '{payment,amount}', to_jsonb(round((v.data #>> '{payment,amount}')::numeric, 2))),
+                '{payment,delivery_cost}', to_jsonb(round((v.data #>> '{payment,delivery_cost}')::numeric, 2))),
+            '{payment,custom_fee}', to_jsonb(round((v.data #>> '{payment,custom_fee}')::numeric, 2))),
+        '{items}',
+        case when jsonb_typeof(v.data -> 'items') = 'array' then coalesce((
+            select jsonb_agg(i.item || jsonb_build_object(
+                'price', round((i.item ->> 'price')::numeric, 2),
+                'total_price', round((i.item ->> 'total_price')::numeric, 2)) order by i.n)
+            from jsonb_array_elements(v.data -> 'items') with ordinality as i(item, n)
+        ), '[]'::jsonb) else coalesce(v.data -> 'items', 'null'::jsonb) end)
+where v.data #> '{payment,amount}' is not null
+  and v.data #> '{payment,delivery_cost}' is not null
+  and v.data #> '{payment,custom_fee}' is not null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table payments
+    alter column amount type decimal,
+    alter column delivery_cost type decimal,
+    alter column custom_fee type decimal;
+
+alter table items
+    alter column price type decimal,
+    alter column total_price type decimal;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Rebuilding the search document once per item row is quadratic in the
+-- number of items of an order. Statement triggers rebuild it once for every
+-- order an insert, update or delete touched.
+drop trigger items_search_changed on items;
+
+create function order_search_items_changed() returns trigger as $$
+begin
+    perform refresh_order_search(c.order_uid)
+    from (select distinct order_uid from changed_items) c;
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger items_search_inserted after insert on items
+    referencing new table as changed_items
+    for each statement execute function order_search_items_changed();
+create trigger items_search_updated after update on items
+    referencing new table as changed_items
+    for each statement execute function order_search_items_changed();
+create trigger items_search_deleted after delete on items
+    referencing old table as changed_items
+    for each statement execute function order_search_items_changed();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop trigger items_search_deleted on items;
+drop trigger items_search_updated on items;
+drop trigger items_search_inserted on items;
+
+drop function order_search_items_changed();
+
+create trigger items_search_changed after insert or update or delete on items
+    for each row execute function order_search_changed();
+-- +goose StatementEnd
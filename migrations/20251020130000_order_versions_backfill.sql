@@ -0,0 +1,87 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Every write records a version; writes of one transaction update the
+-- version it recorded first, which tx_id identifies.
+alter table order_versions add column tx_id bigint;
+create unique index idx_order_versions_tx_id on order_versions(order_uid, tx_id);
+
+-- Orders written before versions were recorded get their current state as
+-- version 1, in the JSON the repository writes. It is dated at the creation
+-- of the order, the earliest state as_of reads can be answered with.
+insert into order_versions(order_uid, version, source, created_at, data)
+select o.order_uid, 1, 'backfill', o.date_created, jsonb_strip_nulls(jsonb_build_object(
+    'order_uid', o.order_uid,
+    'track_number', o.track_number,
+    'entry', o.entry,
+    'delivery', jsonb_build_object(
+        'name', d.name,
+        'phone', d.phone,
+        'zip', d.zip,
+        'city', d.city,
+        'address', d.address,
+        'region', d.region,
+        'email', d.email
+    ),
+    'payment', jsonb_build_object(
+        'transaction', p.transaction,
+        'request', p.request_id,
+        'currency', p.currency,
+        'provider', p.provider,
+        'amount', p.amount,
+        'payment_dt', p.payment_dt,
+        'bank', p.bank,
+        'delivery_cost', p.delivery_cost,
+        'goods_total', p.goods_total,
+        'custom_fee', p.custom_fee
+    ),
+    'items', (
+        select jsonb_agg(jsonb_build_object(
+            'chrt_id', i.chrt_id,
+            'track_number', i.track_number,
+            'price', i.price,
+            'rid', i.rid,
+            'name', i.name,
+            'sale', i.sale,
+            'size', i.size,
+            'total_price', i.total_price,
+            'nm_id', i.nm_id,
+            'brand', i.brand,
+            'status', i.status
+        ) order by i.id)
+        from items i
+        where i.order_uid = o.order_uid and i.date_created = o.date_created
+    ),
+    'locale', o.locale,
+    'internal_signature', o.internal_signature,
+    'customer_id', o.customer_id,
+    'delivery_service', o.delivery_service,
+    'shardkey', o.shardKey,
+    'sm_id', o.sm_id,
+    'date_created', to_char(o.date_created, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+    'oof_shard', o.oof_shard,
+    'status', o.status,
+    'timeline', (
+        select jsonb_agg(jsonb_build_object(
+            'from', h.from_status,
+            'to', h.to_status,
+            'reason', h.reason,
+            'changed_at', to_char(h.changed_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+        ) order by h.changed_at, h.id)
+        from order_status_history h
+        where h.order_uid = o.order_uid
+    )
+))
+from orders o
+left join deliveries d on d.order_uid = o.order_uid and d.date_created = o.date_created
+left join payments p on p.order_uid = o.order_uid and p.date_created = o.date_created
+where not exists (select 1 from order_versions v where v.order_uid = o.order_uid);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+delete from order_versions where source = 'backfill';
+
+drop index idx_order_versions_tx_id;
+alter table order_versions drop column tx_id;
+-- +goose StatementEnd
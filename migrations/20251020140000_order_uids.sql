@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The primary key of the partitioned orders includes date_created, so it no
+-- longer keeps order_uid unique. order_uids does, and tells which partition
+-- an order lives in. The trigger keeps it in step with orders in the same
+-- transaction; a second order with a known uid fails with a unique violation.
+create table order_uids (
+    order_uid varchar(255) primary key,
+    date_created timestamp not null
+);
+
+insert into order_uids(order_uid, date_created)
+select order_uid, min(date_created)
+from orders
+group by order_uid;
+
+create function register_order_uid() returns trigger as $$
+begin
+    if tg_op in ('UPDATE', 'DELETE') then
+        delete from order_uids where order_uid = old.order_uid and date_created = old.date_created;
+    end if;
+    if tg_op in ('INSERT', 'UPDATE') then
+        insert into order_uids(order_uid, date_created) values (new.order_uid, new.date_created);
+    end if;
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger orders_register_uid after insert or update of order_uid, date_created or delete on orders
+    for each row execute function register_order_uid();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop trigger orders_register_uid on orders;
+drop function register_order_uid();
+drop table order_uids;
+-- +goose StatementEnd
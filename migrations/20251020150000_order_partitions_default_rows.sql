@@ -0,0 +1,87 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- A partition cannot be created while the default partition holds rows of
+-- its range, and rows left in the default partition are never archived.
+-- create_order_partition moves the rows of the month out of the default
+-- partition of each table and back in through the new partition.
+create function create_order_partition(month timestamp) returns void as $$
+declare
+    tbl text;
+    partition text;
+begin
+    month := date_trunc('month', month);
+    foreach tbl in array array['orders', 'deliveries', 'payments', 'items'] loop
+        partition := tbl || to_char(month, '"_p"YYYY_MM');
+        continue when to_regclass(partition) is not null;
+
+        -- Writes of the month wait, so none is lost between copy and delete.
+        execute format('lock table %I in exclusive mode', tbl || '_default');
+        execute format(
+            'create temporary table order_partition_rows as select * from %I where date_created >= %L and date_created < %L',
+            tbl || '_default', month, month + interval '1 month'
+        );
+        execute format(
+            'delete from %I where date_created >= %L and date_created < %L',
+            tbl || '_default', month, month + interval '1 month'
+        );
+        execute format(
+            'create table %I partition of %I for values from (%L) to (%L)',
+            partition, tbl, month, month + interval '1 month'
+        );
+        execute format(
+            'insert into %I %s select * from order_partition_rows',
+            tbl, case when tbl = 'items' then 'overriding system value' else '' end
+        );
+        drop table order_partition_rows;
+    end loop;
+end;
+$$ language plpgsql;
+
+-- create_order_partitions creates the monthly partitions of all order tables
+-- for every month from from_month to to_month inclusive, and for every month
+-- that has rows in a default partition.
+create or replace function create_order_partitions(from_month timestamp, to_month timestamp) returns void as $$
+declare
+    month timestamp := date_trunc('month', from_month);
+begin
+    while month <= to_month loop
+        perform create_order_partition(month);
+        month := month + interval '1 month';
+    end loop;
+
+    for month in
+        select date_trunc('month', date_created) from orders_default
+        union select date_trunc('month', date_created) from deliveries_default
+        union select date_trunc('month', date_created) from payments_default
+        union select date_trunc('month', date_created) from items_default
+    loop
+        perform create_order_partition(month);
+    end loop;
+end;
+$$ language plpgsql;
+
+select create_order_partitions(now()::timestamp, now()::timestamp);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+create or replace function create_order_partitions(from_month timestamp, to_month timestamp) returns void as $$
+declare
+    month timestamp := date_trunc('month', from_month);
+    tbl text;
+begin
+    while month <= to_month loop
+        foreach tbl in array array['orders', 'deliveries', 'payments', 'items'] loop
+            execute format(
+                'create table if not exists %I partition of %I for values from (%L) to (%L)',
+                tbl || to_char(month, '"_p"YYYY_MM'), tbl, month, month + interval '1 month'
+            );
+        end loop;
+        month := month + interval '1 month';
+    end loop;
+end;
+$$ language plpgsql;
+
+drop function create_order_partition(timestamp);
+-- +goose StatementEnd
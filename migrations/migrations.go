@@ -0,0 +1,8 @@
+package migrations
+
+import "embed"
+
+// FS holds the SQL migrations, so the server binary can apply them itself.
+//
+//go:embed *.sql
+var FS embed.FS